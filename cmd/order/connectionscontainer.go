@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"io"
 
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
 	"github.com/jmoiron/sqlx"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+
+	"order/api/client/productcatalog"
+	"order/pkg/infrastructure/grpcclient"
 )
 
 type multiCloser struct {
@@ -37,7 +41,7 @@ func (m *multiCloser) Close() error {
 
 func newConnectionsContainer(
 	config *config,
-	_ *log.Logger,
+	logger *log.Logger,
 	multiCloser *multiCloser,
 ) (container *connectionsContainer, err error) {
 	containerBuilder := func() error {
@@ -52,21 +56,34 @@ func newConnectionsContainer(
 		if err = applyMigrations(db.DB, pathToMigrations); err != nil {
 			return fmt.Errorf("migration failed: %w", err)
 		}
-		log.Infof("Migrations applied successfully")
-		multiCloser.Add(db)
-		container.db = db
-
-		// TODO: это конекшены к другим сервисам (в данном случае - gRPC)
-		testConnection, err := grpc.NewClient(
-			config.TestGRPCAddress,
-			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		logger.Infof("Migrations applied successfully")
+
+		container.mysqlClient, err = openMySQL(config, multiCloser)
+		if err != nil {
+			return err
+		}
+
+		productCatalogConnection, err := grpc.NewClient(
+			config.ProductCatalogGRPCAddress,
+			append(
+				grpcclient.DialOptions(grpcclient.Config{
+					Timeout:                 config.ProductCatalogTimeout,
+					MaxRetries:              config.ProductCatalogMaxRetries,
+					RetryBaseDelay:          config.ProductCatalogRetryBaseDelay,
+					RetryMaxDelay:           config.ProductCatalogRetryMaxDelay,
+					IdempotentMethods:       []string{productcatalog.ProductCatalogService_GetProductPrice_FullMethodName},
+					BreakerFailureThreshold: config.ProductCatalogBreakerFailureThreshold,
+					BreakerOpenTimeout:      config.ProductCatalogBreakerOpenTimeout,
+				}),
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+			)...,
 		)
 		if err != nil {
 			return err
 		}
 
-		multiCloser.Add(testConnection)
-		container.testConnection = testConnection
+		multiCloser.Add(productCatalogConnection)
+		container.productCatalogConnection = productCatalogConnection
 
 		return nil
 	}
@@ -74,13 +91,40 @@ func newConnectionsContainer(
 	return container, containerBuilder()
 }
 
+// newRelayConnectionsContainer only connects to the database, the relay neither applies migrations nor calls the catalog
+func newRelayConnectionsContainer(
+	config *config,
+	multiCloser *multiCloser,
+) (*connectionsContainer, error) {
+	mysqlClient, err := openMySQL(config, multiCloser)
+	if err != nil {
+		return nil, err
+	}
+	return &connectionsContainer{mysqlClient: mysqlClient}, nil
+}
+
 type connectionsContainer struct {
-	db             *sqlx.DB
-	testConnection grpc.ClientConnInterface
+	mysqlClient              mysql.TransactionalClient
+	productCatalogConnection grpc.ClientConnInterface
+}
+
+func openMySQL(config *config, multiCloser *multiCloser) (mysql.TransactionalClient, error) {
+	connector := mysql.NewConnector()
+	err := connector.Open(config.buildDSN(), mysql.Config{
+		MaxConnections:        config.DBMaxConn,
+		ConnectionMaxLifeTime: config.DBConnMaxLifeTime,
+		ConnectionMaxIdleTime: config.DBConnMaxIdleTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MySQL: %w", err)
+	}
+	multiCloser.Add(connector)
+	return connector.TransactionalClient(), nil
 }
 
+// initMySQL connects for migrations, which may consist of several statements
 func initMySQL(cfg *config) (db *sqlx.DB, err error) {
-	db, err = sqlx.Connect("mysql", cfg.buildDSN())
+	db, err = sqlx.Connect("mysql", cfg.buildDSN()+"&multiStatements=true")
 	if err != nil || db == nil {
 		return nil, fmt.Errorf("failed to connect to MySQL: %w", err)
 	}
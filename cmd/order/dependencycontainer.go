@@ -1,18 +1,69 @@
 package main
 
-import "github.com/jmoiron/sqlx"
+import (
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/prometheus/client_golang/prometheus"
 
-// TODO: добавить зависимости
+	appservice "order/pkg/application/service"
+	"order/pkg/domain/model"
+	domainservice "order/pkg/domain/service"
+	"order/pkg/infrastructure/catalog"
+	"order/pkg/infrastructure/integrationevent"
+	inframysql "order/pkg/infrastructure/mysql"
+)
 
 func newDependencyContainer(
-	_ *config,
+	config *config,
 	connContainer *connectionsContainer,
 ) (*dependencyContainer, error) {
+	pool := mysql.NewConnectionPool(connContainer.mysqlClient)
+	uow := mysql.NewUnitOfWork(pool, inframysql.NewRepositoryProvider)
+	luow := mysql.NewLockableUnitOfWork(uow, mysql.NewLocker(pool))
+
+	eventDispatcher := integrationevent.NewEventDispatcher(
+		outboxTransport,
+		integrationevent.NewEventSerializer(),
+		uow,
+	)
+
+	catalogProvider := catalog.NewProductProvider(connContainer.productCatalogConnection)
+	unitOfWork := inframysql.NewUnitOfWork(uow, config.DBMaxRetries)
+	lockableUnitOfWork := inframysql.NewLockableUnitOfWork(luow, config.DBLockTimeout, config.DBMaxRetries)
+	orderService := appservice.NewOrderService(
+		unitOfWork,
+		lockableUnitOfWork,
+		eventDispatcher,
+		catalog.NewCachingProductProvider(
+			catalogProvider,
+			catalog.CacheOptions{
+				TTL:         config.PriceCacheTTL,
+				NegativeTTL: config.PriceCacheNegativeTTL,
+				MaxEntries:  config.PriceCacheMaxEntries,
+			},
+			prometheus.DefaultRegisterer,
+		),
+		catalogProvider,
+		domainservice.Options{
+			PricingRules: model.PricingRules{
+				DiscountRate: config.PricingDiscountRate,
+				TaxRate:      config.PricingTaxRate,
+			},
+			MergeDuplicateItems: config.MergeDuplicateItems,
+		},
+	)
+
+	idempotencyService := appservice.NewIdempotencyService(unitOfWork, lockableUnitOfWork, appservice.IdempotencyOptions{
+		TTL:            config.IdempotencyKeyTTL,
+		PendingTimeout: config.IdempotencyKeyPendingTimeout,
+	})
+
 	return &dependencyContainer{
-		db: connContainer.db,
+		orderService:       orderService,
+		idempotencyService: idempotencyService,
 	}, nil
 }
 
 type dependencyContainer struct {
-	db *sqlx.DB
+	orderService       appservice.OrderService
+	idempotencyService appservice.IdempotencyService
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	api "order/api/server/order"
+	appservice "order/pkg/application/service"
+)
+
+// idempotentMethods are mutating methods which accept idempotency-key header
+var idempotentMethods = []string{
+	api.OrderService_CreateOrder_FullMethodName,
+	api.OrderService_AddProduct_FullMethodName,
+	api.OrderService_ChangeQuantity_FullMethodName,
+	api.OrderService_RemoveItem_FullMethodName,
+	api.OrderService_SetStatus_FullMethodName,
+	api.OrderService_CancelOrder_FullMethodName,
+	api.OrderService_DeleteOrder_FullMethodName,
+	api.OrderService_Checkout_FullMethodName,
+}
+
+// runIdempotencyKeyCleaner deletes expired idempotency keys until ctx is done
+func runIdempotencyKeyCleaner(
+	ctx context.Context,
+	idempotencyService appservice.IdempotencyService,
+	interval time.Duration,
+	logger *log.Logger,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := idempotencyService.DeleteExpired(ctx); err != nil {
+				logger.Errorf("failed to delete expired idempotency keys: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// newLibLogger adapts logrus logger to the logger interface expected by golib components
+func newLibLogger(logger log.FieldLogger) logging.Logger {
+	return &libLogger{
+		FieldLogger: logger,
+	}
+}
+
+type libLogger struct {
+	log.FieldLogger
+}
+
+func (l *libLogger) WithField(key string, value interface{}) logging.Logger {
+	return &libLogger{l.FieldLogger.WithField(key, value)}
+}
+
+func (l *libLogger) WithFields(fields logging.Fields) logging.Logger {
+	return &libLogger{l.FieldLogger.WithFields(log.Fields(fields))}
+}
+
+func (l *libLogger) Error(err error, args ...interface{}) {
+	l.FieldLogger.WithError(err).Error(args...)
+}
+
+func (l *libLogger) Warning(err error, args ...interface{}) {
+	l.FieldLogger.WithError(err).Warn(args...)
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
+
+	"order/pkg/infrastructure/requestid"
 )
 
 // TODO:  appID используется как префикс для env-переменных
@@ -57,6 +59,7 @@ func runApp(
 		Name: appID,
 		Commands: []*cli.Command{
 			service(config, logger, closer),
+			relay(config, logger, closer),
 			migrate(config, logger),
 		},
 	}
@@ -75,6 +78,7 @@ func initLogger(level string) (*log.Logger, error) {
 	logger.SetFormatter(&log.JSONFormatter{
 		TimestampFormat: "2006-01-02T15:04:05.000Z07:00",
 	})
+	logger.AddHook(requestid.NewLogHook())
 
 	return logger, nil
 }
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+const metricsReadHeaderTimeout = 5 * time.Second
+
+// startMetricsServer serves Prometheus metrics from the default registry
+func startMetricsServer(config *config, logger *log.Logger) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	server := &http.Server{
+		Addr:              config.ServeMetricsAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: metricsReadHeaderTimeout,
+	}
+	go func() {
+		logger.Infof("Metrics server listening on %s", config.ServeMetricsAddress)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("metrics server failed: %v", err)
+		}
+	}()
+	return server
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/outbox"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"order/pkg/infrastructure/publisher"
+)
+
+// outboxTransport names outbox tables, see outbox_order_* migrations
+const outboxTransport = "order"
+
+func relay(
+	config *config,
+	logger *log.Logger,
+	closer *multiCloser,
+) *cli.Command {
+	return &cli.Command{
+		Name:  "relay",
+		Usage: "Publishes events stored in the outbox",
+		Action: func(c *cli.Context) error {
+			connContainer, err := newRelayConnectionsContainer(config, closer)
+			if err != nil {
+				return errors.Wrap(err, "failed to init connections")
+			}
+
+			eventPublisher, err := newPublisher(config)
+			if err != nil {
+				return errors.Wrap(err, "failed to init event publisher")
+			}
+			closer.Add(eventPublisher)
+
+			handler := outbox.NewEventHandler(
+				outboxTransport,
+				publisher.NewOutboxTransport(eventPublisher),
+				config.OutboxBatchSize,
+				config.OutboxSendInterval,
+				config.DBLockTimeout,
+				mysql.NewConnectionPool(connContainer.mysqlClient),
+				newLibLogger(logger),
+			)
+			logger.Infof("Outbox relay started")
+			return handler.Start(c.Context)
+		},
+	}
+}
+
+func newPublisher(config *config) (publisher.Publisher, error) {
+	switch config.EventPublisher {
+	case "file":
+		return publisher.NewFilePublisher(config.EventFilePath)
+	case "amqp":
+		return publisher.NewAMQPPublisher(config.AMQPURL, config.AMQPExchange)
+	default:
+		return nil, errors.Errorf("unknown event publisher %q", config.EventPublisher)
+	}
+}
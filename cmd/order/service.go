@@ -3,14 +3,17 @@ package main
 import (
 	"context"
 	"net"
+	"slices"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 	"google.golang.org/grpc"
 
-	api "order/api/server/orderinternal"
+	api "order/api/server/order"
+	internalapi "order/api/server/orderinternal"
 	"order/pkg/infrastructure/transport"
 )
 
@@ -25,6 +28,10 @@ func service(
 		Name:  "service",
 		Usage: "Runs the gRPC service",
 		Action: func(c *cli.Context) error {
+			if err := checkAuthConfig(config); err != nil {
+				return err
+			}
+
 			connContainer, err := newConnectionsContainer(config, logger, closer)
 			if err != nil {
 				return errors.Wrap(err, "failed to init connections")
@@ -34,21 +41,36 @@ func service(
 			if err != nil {
 				return errors.Wrap(err, "failed to init dependencies")
 			}
+
+			closer.Add(startMetricsServer(config, logger))
+			go runIdempotencyKeyCleaner(c.Context, container.idempotencyService, config.IdempotencyKeyCleanupInterval, logger)
 			return startGRPCServer(c.Context, config, logger, container)
 		},
 	}
 }
 
+// checkAuthConfig fails startup when auth interceptor has no tokens, since it would reject every call
+func checkAuthConfig(config *config) error {
+	if slices.Contains(config.GRPCInterceptors, "auth") && len(config.GRPCAuthTokens) == 0 {
+		return errors.New("auth interceptor is enabled, but no grpc auth tokens are configured")
+	}
+	return nil
+}
+
 func startGRPCServer(
 	ctx context.Context,
 	config *config,
 	logger *log.Logger,
-	_ *dependencyContainer,
+	container *dependencyContainer,
 ) error {
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(makeGrpcUnaryInterceptor(logger)))
+	serverOptions, err := buildInterceptorChain(config, logger, container)
+	if err != nil {
+		return errors.Wrap(err, "failed to build interceptor chain")
+	}
+	grpcServer := grpc.NewServer(serverOptions...)
 
-	// TODO: зарегистрировать свой сервер вместо шаблонного
-	api.RegisterOrderInternalServiceServer(grpcServer, transport.NewInternalAPI())
+	api.RegisterOrderServiceServer(grpcServer, transport.NewOrderAPI(container.orderService))
+	internalapi.RegisterOrderInternalServiceServer(grpcServer, transport.NewInternalAPI())
 
 	listener, err := net.Listen("tcp", config.ServeGRPCAddress)
 	if err != nil {
@@ -87,11 +109,40 @@ func shutdownGRPCServer(server *grpc.Server, logger *log.Logger) {
 	}
 }
 
-func makeGrpcUnaryInterceptor(logger *log.Logger) grpc.UnaryServerInterceptor {
-	loggerInterceptor := transport.MakeLoggerServerInterceptor(logger)
+func buildInterceptorChain(
+	config *config,
+	logger *log.Logger,
+	container *dependencyContainer,
+) ([]grpc.ServerOption, error) {
+	recoveryInterceptor := transport.NewRecoveryInterceptor(logger, prometheus.DefaultRegisterer)
+	metricsInterceptor := transport.NewMetricsInterceptor(prometheus.DefaultRegisterer)
+	authInterceptor := transport.NewTokenAuthInterceptor(config.GRPCAuthTokens)
 	errorInterceptor := transport.ErrorInterceptor{Logger: logger}
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-		resp, err = loggerInterceptor(ctx, req, info, handler)
-		return resp, errorInterceptor.TranslateGRPCError(err)
-	}
+
+	return transport.NewChainBuilder().
+		Register("requestid", transport.RequestIDInterceptor()).
+		Register("logging", transport.Interceptor{
+			Unary:  transport.MakeLoggerServerInterceptor(logger),
+			Stream: transport.MakeLoggerStreamServerInterceptor(logger),
+		}).
+		Register("metrics", transport.Interceptor{
+			Unary:  metricsInterceptor.Unary(),
+			Stream: metricsInterceptor.Stream(),
+		}).
+		Register("errors", transport.Interceptor{
+			Unary:  errorInterceptor.Unary(),
+			Stream: errorInterceptor.Stream(),
+		}).
+		Register("recovery", transport.Interceptor{
+			Unary:  recoveryInterceptor.Unary(),
+			Stream: recoveryInterceptor.Stream(),
+		}).
+		Register("auth", transport.Interceptor{
+			Unary:  authInterceptor.Unary(),
+			Stream: authInterceptor.Stream(),
+		}).
+		Register("idempotency", transport.Interceptor{
+			Unary: transport.MakeIdempotencyServerInterceptor(container.idempotencyService, idempotentMethods),
+		}).
+		Build(config.GRPCInterceptors)
 }
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS item;
+DROP TABLE IF EXISTS orders;
\ No newline at end of file
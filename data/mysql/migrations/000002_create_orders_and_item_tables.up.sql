@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS orders
+(
+    `order_id`    VARCHAR(36) NOT NULL,
+    `customer_id` VARCHAR(36) NOT NULL,
+    `status`      TINYINT     NOT NULL,
+    `created_at`  DATETIME(6) NOT NULL,
+    `updated_at`  DATETIME(6) NOT NULL,
+    `deleted_at`  DATETIME(6) NULL,
+    PRIMARY KEY (`order_id`),
+    INDEX `customer_id_status_idx` (`customer_id`, `status`)
+) ENGINE = InnoDB
+  CHARACTER SET = utf8mb4
+  COLLATE utf8mb4_unicode_ci
+;
+
+CREATE TABLE IF NOT EXISTS item
+(
+    `item_id`    VARCHAR(36) NOT NULL,
+    `order_id`   VARCHAR(36) NOT NULL,
+    `product_id` VARCHAR(36) NOT NULL,
+    `price`      DOUBLE      NOT NULL,
+    PRIMARY KEY (`item_id`),
+    INDEX `order_id_idx` (`order_id`)
+) ENGINE = InnoDB
+  CHARACTER SET = utf8mb4
+  COLLATE utf8mb4_unicode_ci
+;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS outbox_order_event;
\ No newline at end of file
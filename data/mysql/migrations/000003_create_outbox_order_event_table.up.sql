@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS outbox_order_event
+(
+    `event_id`       BIGINT         NOT NULL AUTO_INCREMENT,
+    `correlation_id` VARBINARY(128) NOT NULL,
+    `event_type`     VARBINARY(128) NOT NULL,
+    `payload`        TEXT           NOT NULL,
+    PRIMARY KEY (`event_id`)
+) ENGINE = InnoDB
+  CHARACTER SET = utf8mb4
+  COLLATE utf8mb4_unicode_ci
+;
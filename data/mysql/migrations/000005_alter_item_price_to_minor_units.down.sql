@@ -0,0 +1,7 @@
+ALTER TABLE item
+    MODIFY `price` DOUBLE NOT NULL
+;
+UPDATE item SET `price` = `price` / 100;
+ALTER TABLE item
+    DROP COLUMN `currency`
+;
@@ -0,0 +1,9 @@
+-- prices stored before money had currency are roubles, they are converted to kopecks
+ALTER TABLE item
+    ADD COLUMN `currency` CHAR(3) NOT NULL DEFAULT 'RUB' AFTER `price`
+;
+UPDATE item SET `price` = ROUND(`price` * 100);
+ALTER TABLE item
+    MODIFY `price` BIGINT NOT NULL,
+    ALTER COLUMN `currency` DROP DEFAULT
+;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS order_total;
\ No newline at end of file
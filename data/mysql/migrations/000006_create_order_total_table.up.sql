@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS order_total
+(
+    `order_id` VARCHAR(36) NOT NULL,
+    `currency` CHAR(3)     NOT NULL,
+    `subtotal` BIGINT      NOT NULL,
+    `discount` BIGINT      NOT NULL,
+    `tax`      BIGINT      NOT NULL,
+    `total`    BIGINT      NOT NULL,
+    PRIMARY KEY (`order_id`, `currency`)
+) ENGINE = InnoDB
+  CHARACTER SET = utf8mb4
+  COLLATE utf8mb4_unicode_ci
+;
@@ -0,0 +1 @@
+ALTER TABLE item DROP COLUMN `quantity`;
\ No newline at end of file
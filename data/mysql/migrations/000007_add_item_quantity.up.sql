@@ -0,0 +1,3 @@
+ALTER TABLE item
+    ADD COLUMN `quantity` INT NOT NULL DEFAULT 1 AFTER `currency`
+;
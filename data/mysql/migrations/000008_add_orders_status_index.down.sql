@@ -0,0 +1 @@
+DROP INDEX `status_idx` ON orders;
@@ -0,0 +1 @@
+CREATE INDEX `status_idx` ON orders (`status`);
@@ -0,0 +1,3 @@
+ALTER TABLE orders
+    ADD COLUMN `version` BIGINT NOT NULL DEFAULT 1 AFTER `status`
+;
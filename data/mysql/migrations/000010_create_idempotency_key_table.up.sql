@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS idempotency_key
+(
+    `idempotency_key` VARCHAR(255) NOT NULL,
+    `request_hash`    BINARY(32)   NOT NULL,
+    `completed`       TINYINT(1)   NOT NULL,
+    `response`        MEDIUMBLOB   NULL,
+    `expires_at`      DATETIME(6)  NOT NULL,
+    PRIMARY KEY (`idempotency_key`),
+    INDEX `expires_at_idx` (`expires_at`)
+) ENGINE = InnoDB
+  CHARACTER SET = utf8mb4
+  COLLATE utf8mb4_unicode_ci
+;
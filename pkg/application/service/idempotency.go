@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"order/pkg/domain/model"
+)
+
+var (
+	ErrIdempotencyKeyReused        = model.NewError(model.ConflictError, "IDEMPOTENCY_KEY_REUSED", "idempotency key is already used for another request")
+	ErrIdempotentRequestInProgress = model.NewError(model.AbortedError, "IDEMPOTENT_REQUEST_IN_PROGRESS", "request with the same idempotency key is in progress")
+)
+
+// IdempotencyRecord is a response stored for an idempotency key, it is not completed while the request is in progress
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash []byte
+	Completed   bool
+	Response    []byte
+	ExpiresAt   time.Time
+}
+
+type IdempotencyRepository interface {
+	// Find locks the record of the key until the end of transaction, returns nil when there is no record
+	Find(key string) (*IdempotencyRecord, error)
+	Store(record IdempotencyRecord) error
+	Delete(key string) error
+	DeleteExpired(before time.Time) error
+}
+
+type IdempotencyService interface {
+	// Execute calls f once per key and stores its response, repeated requests with the key get the stored response.
+	// f must use the passed context, so its changes are committed together with the response.
+	// Key reuse for a request with another hash fails with ErrIdempotencyKeyReused
+	Execute(ctx context.Context, key string, requestHash []byte, f func(ctx context.Context) ([]byte, error)) ([]byte, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+type IdempotencyOptions struct {
+	// TTL is how long responses are kept for replay
+	TTL time.Duration
+	// PendingTimeout is how long the key stays reserved for a request that has not finished, e.g. after a crash
+	PendingTimeout time.Duration
+}
+
+func NewIdempotencyService(uow UnitOfWork, luow LockableUnitOfWork, options IdempotencyOptions) IdempotencyService {
+	return &idempotencyService{
+		uow:     uow,
+		luow:    luow,
+		options: options,
+	}
+}
+
+type idempotencyService struct {
+	uow     UnitOfWork
+	luow    LockableUnitOfWork
+	options IdempotencyOptions
+}
+
+func (s idempotencyService) Execute(
+	ctx context.Context,
+	key string,
+	requestHash []byte,
+	f func(ctx context.Context) ([]byte, error),
+) (response []byte, err error) {
+	stored, err := s.reserve(ctx, key, requestHash)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil {
+		switch {
+		case !bytes.Equal(stored.RequestHash, requestHash):
+			return nil, ErrIdempotencyKeyReused
+		case !stored.Completed:
+			return nil, ErrIdempotentRequestInProgress
+		default:
+			return stored.Response, nil
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = s.release(ctx, key)
+			panic(r)
+		}
+	}()
+
+	// response is stored in the transaction of f, so a crash can not leave changes without the response.
+	// The transaction is run by a lockable unit of work, so locks taken by f are held until it is committed
+	txCtx := WithSharedTransaction(ctx)
+	err = s.luow.Execute(txCtx, idempotencyLockName(key), func(provider RepositoryProvider) error {
+		var err error
+		response, err = f(txCtx)
+		if err != nil {
+			return err
+		}
+		return provider.IdempotencyRepository(txCtx).Store(IdempotencyRecord{
+			Key:         key,
+			RequestHash: requestHash,
+			Completed:   true,
+			Response:    response,
+			ExpiresAt:   time.Now().Add(s.options.TTL),
+		})
+	})
+	if err != nil {
+		// failed request does not keep the key, so it may be retried
+		if releaseErr := s.release(ctx, key); releaseErr != nil {
+			return nil, errors.Join(err, releaseErr)
+		}
+		return nil, err
+	}
+	return response, nil
+}
+
+// idempotencyLockName may be truncated for long keys, colliding names only make requests wait for each other
+func idempotencyLockName(key string) string {
+	return "idempotency_" + key
+}
+
+// release deletes the pending record even if the client has gone away, otherwise the key stays reserved until PendingTimeout
+func (s idempotencyService) release(ctx context.Context, key string) error {
+	ctx = context.WithoutCancel(ctx)
+	return s.uow.Execute(ctx, func(provider RepositoryProvider) error {
+		return provider.IdempotencyRepository(ctx).Delete(key)
+	})
+}
+
+func (s idempotencyService) DeleteExpired(ctx context.Context) error {
+	return s.uow.Execute(ctx, func(provider RepositoryProvider) error {
+		return provider.IdempotencyRepository(ctx).DeleteExpired(time.Now())
+	})
+}
+
+// reserve stores a pending record for the key, returns the record if the key is already in use
+func (s idempotencyService) reserve(ctx context.Context, key string, requestHash []byte) (*IdempotencyRecord, error) {
+	var stored *IdempotencyRecord
+	err := s.uow.Execute(ctx, func(provider RepositoryProvider) error {
+		repo := provider.IdempotencyRepository(ctx)
+		record, err := repo.Find(key)
+		if err != nil {
+			return err
+		}
+		if record != nil && record.ExpiresAt.After(time.Now()) {
+			stored = record
+			return nil
+		}
+
+		return repo.Store(IdempotencyRecord{
+			Key:         key,
+			RequestHash: requestHash,
+			ExpiresAt:   time.Now().Add(s.options.PendingTimeout),
+		})
+	})
+	return stored, err
+}
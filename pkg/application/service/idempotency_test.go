@@ -0,0 +1,181 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+)
+
+func TestIdempotencyService(t *testing.T) {
+	repo := &mockIdempotencyRepository{records: map[string]service.IdempotencyRecord{}}
+	uow := &mockUnitOfWork{repo: repo}
+	idempotencyService := service.NewIdempotencyService(uow, &lockingUnitOfWork{uow: uow}, service.IdempotencyOptions{
+		TTL:            time.Hour,
+		PendingTimeout: time.Minute,
+	})
+	ctx := context.Background()
+
+	calls := 0
+	f := func(context.Context) ([]byte, error) {
+		calls++
+		return []byte("response"), nil
+	}
+
+	t.Run("Repeated request gets stored response", func(t *testing.T) {
+		response, err := idempotencyService.Execute(ctx, "key", []byte("hash"), f)
+		require.NoError(t, err)
+		require.Equal(t, []byte("response"), response)
+
+		response, err = idempotencyService.Execute(ctx, "key", []byte("hash"), f)
+		require.NoError(t, err)
+		require.Equal(t, []byte("response"), response)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("Key can not be reused for another request", func(t *testing.T) {
+		_, err := idempotencyService.Execute(ctx, "key", []byte("another hash"), f)
+		require.ErrorIs(t, err, service.ErrIdempotencyKeyReused)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("Request in progress is not executed twice", func(t *testing.T) {
+		_, err := idempotencyService.Execute(ctx, "pending", []byte("hash"), func(context.Context) ([]byte, error) {
+			_, err := idempotencyService.Execute(ctx, "pending", []byte("hash"), f)
+			require.ErrorIs(t, err, service.ErrIdempotentRequestInProgress)
+			return nil, nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Failed request releases the key", func(t *testing.T) {
+		errFailed := errors.New("failed")
+		_, err := idempotencyService.Execute(ctx, "failed", []byte("hash"), func(context.Context) ([]byte, error) {
+			return nil, errFailed
+		})
+		require.ErrorIs(t, err, errFailed)
+
+		_, err = idempotencyService.Execute(ctx, "failed", []byte("hash"), f)
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("Panicking request releases the key", func(t *testing.T) {
+		require.Panics(t, func() {
+			_, _ = idempotencyService.Execute(ctx, "panic", []byte("hash"), func(context.Context) ([]byte, error) {
+				panic("implement me")
+			})
+		})
+		require.NotContains(t, repo.records, "panic")
+	})
+
+	t.Run("Response is stored in the transaction of the request", func(t *testing.T) {
+		uow := &mockUnitOfWork{repo: repo}
+		luow := &lockingUnitOfWork{uow: uow}
+		idempotencyService := service.NewIdempotencyService(uow, luow, service.IdempotencyOptions{TTL: time.Hour})
+		_, err := idempotencyService.Execute(ctx, "shared", []byte("hash"), func(ctx context.Context) ([]byte, error) {
+			uow.requestTransaction = service.TransactionContext(ctx)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		require.NotEqual(t, ctx, uow.requestTransaction)
+		require.Equal(t, uow.requestTransaction, uow.storeTransaction)
+		// lockable unit of work runs the transaction, so locks taken by the request are held until its commit
+		require.Equal(t, []string{"idempotency_shared"}, luow.locks)
+	})
+
+	t.Run("Failed response store releases the key", func(t *testing.T) {
+		errStore := errors.New("store failed")
+		repo.storeErr = errStore
+		defer func() { repo.storeErr = nil }()
+
+		_, err := idempotencyService.Execute(ctx, "unstored", []byte("hash"), func(context.Context) ([]byte, error) {
+			return []byte("response"), nil
+		})
+		require.ErrorIs(t, err, errStore)
+		require.NotContains(t, repo.records, "unstored")
+	})
+
+	t.Run("Expired key is executed again", func(t *testing.T) {
+		record := repo.records["key"]
+		record.ExpiresAt = time.Now().Add(-time.Second)
+		repo.records["key"] = record
+
+		_, err := idempotencyService.Execute(ctx, "key", []byte("another hash"), f)
+		require.NoError(t, err)
+		require.Equal(t, 3, calls)
+	})
+}
+
+type mockUnitOfWork struct {
+	repo *mockIdempotencyRepository
+
+	requestTransaction context.Context
+	storeTransaction   context.Context
+	executing          context.Context
+}
+
+func (m *mockUnitOfWork) Execute(ctx context.Context, f func(provider service.RepositoryProvider) error) error {
+	m.executing = service.TransactionContext(ctx)
+	return f(m)
+}
+
+func (m *mockUnitOfWork) OrderRepository(context.Context) model.OrderRepository {
+	panic("unexpected order repository")
+}
+
+func (m *mockUnitOfWork) IdempotencyRepository(context.Context) service.IdempotencyRepository {
+	m.storeTransaction = m.executing
+	return m.repo
+}
+
+type lockingUnitOfWork struct {
+	uow   *mockUnitOfWork
+	locks []string
+}
+
+func (m *lockingUnitOfWork) Execute(ctx context.Context, lockName string, f func(provider service.RepositoryProvider) error) error {
+	m.locks = append(m.locks, lockName)
+	return m.uow.Execute(ctx, f)
+}
+
+type mockIdempotencyRepository struct {
+	records map[string]service.IdempotencyRecord
+	// storeErr fails storing of completed records
+	storeErr error
+}
+
+func (m *mockIdempotencyRepository) Find(key string) (*service.IdempotencyRecord, error) {
+	record, ok := m.records[key]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (m *mockIdempotencyRepository) Store(record service.IdempotencyRecord) error {
+	if record.Completed && m.storeErr != nil {
+		return m.storeErr
+	}
+	m.records[record.Key] = record
+	return nil
+}
+
+func (m *mockIdempotencyRepository) Delete(key string) error {
+	delete(m.records, key)
+	return nil
+}
+
+func (m *mockIdempotencyRepository) DeleteExpired(before time.Time) error {
+	for key, record := range m.records {
+		if record.ExpiresAt.Before(before) {
+			delete(m.records, key)
+		}
+	}
+	return nil
+}
@@ -11,32 +11,130 @@ import (
 	"order/pkg/domain/service"
 )
 
+var ErrInvalidPageLimit = model.NewError(model.InvalidArgumentError, "INVALID_PAGE_LIMIT", "page limit must be positive", model.WithField("page_size"))
+
+// errCheckoutItemsChanged means products of the order changed after their prices were queried
+var errCheckoutItemsChanged = errors.New("order items changed during checkout")
+
+const maxCheckoutAttempts = 3
+
 type ProductProvider interface {
-	ActualPrice(ctx context.Context, productID uuid.UUID) (float64, error)
+	ActualPrice(ctx context.Context, productID uuid.UUID) (model.Money, error)
 }
 
 type OrderService interface {
-	AddProductToOrder(ctx context.Context, customerID uuid.UUID, itemID uuid.UUID) (uuid.UUID, error)
+	CreateOrder(ctx context.Context, customerID uuid.UUID) (uuid.UUID, error)
+	FindOrder(ctx context.Context, orderID uuid.UUID) (*model.Order, error)
+	// ListOrders returns orders matching spec and the next page, which is nil on the last page
+	ListOrders(ctx context.Context, spec model.FindSpec, page model.Page) ([]model.Order, *model.Page, error)
+	AddProductToOrder(ctx context.Context, customerID uuid.UUID, productID uuid.UUID) (orderID uuid.UUID, itemID uuid.UUID, err error)
+	ChangeItemQuantity(ctx context.Context, orderID uuid.UUID, itemID uuid.UUID, quantity int) error
+	RemoveItem(ctx context.Context, orderID uuid.UUID, itemID uuid.UUID) error
+	SetStatus(ctx context.Context, orderID uuid.UUID, status model.OrderStatus) error
+	CancelOrder(ctx context.Context, orderID uuid.UUID, reason model.CancellationReason, actor string) error
+	DeleteOrder(ctx context.Context, orderID uuid.UUID) error
+	// Checkout re-validates item prices against the product catalog and moves the order to Pending
+	Checkout(ctx context.Context, orderID uuid.UUID, acceptPriceChanges bool) ([]model.ItemPriceChange, error)
 }
 
-func NewOrderService() OrderService {
-	return &orderService{}
+func NewOrderService(
+	uow UnitOfWork,
+	luow LockableUnitOfWork,
+	eventDispatcher outbox.EventDispatcher[service.Event],
+	productProvider ProductProvider,
+	catalogProvider ProductProvider,
+	orderOptions service.Options,
+) OrderService {
+	// dependencies are wired once at startup, a missing one must fail there instead of in the first request
+	switch {
+	case uow == nil:
+		panic("unit of work cannot be nil")
+	case luow == nil:
+		panic("lockable unit of work cannot be nil")
+	case eventDispatcher == nil:
+		panic("event dispatcher cannot be nil")
+	case productProvider == nil:
+		panic("product provider cannot be nil")
+	case catalogProvider == nil:
+		panic("catalog provider cannot be nil")
+	}
+
+	return &orderService{
+		uow:             uow,
+		luow:            luow,
+		eventDispatcher: eventDispatcher,
+		productProvider: productProvider,
+		catalogProvider: catalogProvider,
+		orderOptions:    orderOptions,
+	}
 }
 
 type orderService struct {
 	uow             UnitOfWork
 	luow            LockableUnitOfWork
 	eventDispatcher outbox.EventDispatcher[service.Event]
+	// productProvider may serve cached prices, catalogProvider always asks the catalog and is used at checkout
 	productProvider ProductProvider
+	catalogProvider ProductProvider
+	orderOptions    service.Options
+}
+
+func (o orderService) CreateOrder(ctx context.Context, customerID uuid.UUID) (uuid.UUID, error) {
+	var orderID uuid.UUID
+	err := o.luow.Execute(ctx, customerLockName(customerID), func(provider RepositoryProvider) error {
+		domainService := o.domainService(ctx, provider.OrderRepository(ctx))
+		var err error
+		orderID, err = domainService.CreateOrder(customerID)
+		return err
+	})
+	return orderID, err
+}
+
+func (o orderService) FindOrder(ctx context.Context, orderID uuid.UUID) (*model.Order, error) {
+	var order *model.Order
+	err := o.uow.Execute(ctx, func(provider RepositoryProvider) error {
+		var err error
+		order, err = provider.OrderRepository(ctx).Find(model.FindSpec{OrderID: &orderID})
+		return err
+	})
+	return order, err
 }
 
-func (o orderService) AddProductToOrder(ctx context.Context, customerID uuid.UUID, productID uuid.UUID) (uuid.UUID, error) {
+func (o orderService) ListOrders(ctx context.Context, spec model.FindSpec, page model.Page) ([]model.Order, *model.Page, error) {
+	if page.Limit <= 0 {
+		return nil, nil, ErrInvalidPageLimit
+	}
+
+	var orders []model.Order
+	err := o.uow.Execute(ctx, func(provider RepositoryProvider) error {
+		var err error
+		// one extra order tells whether there is a next page
+		orders, err = provider.OrderRepository(ctx).List(spec, model.Page{
+			After: page.After,
+			Limit: page.Limit + 1,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(orders) <= page.Limit {
+		return orders, nil, nil
+	}
+	orders = orders[:page.Limit]
+	return orders, &model.Page{
+		After: orders[len(orders)-1].ID,
+		Limit: page.Limit,
+	}, nil
+}
+
+func (o orderService) AddProductToOrder(ctx context.Context, customerID uuid.UUID, productID uuid.UUID) (uuid.UUID, uuid.UUID, error) {
 	var orderID uuid.UUID
-	err := o.luow.Execute(ctx, "customer_"+customerID.String(), func(provider RepositoryProvider) error {
-		status := model.Open
+	err := o.luow.Execute(ctx, customerLockName(customerID), func(provider RepositoryProvider) error {
 		order, err := provider.OrderRepository(ctx).Find(model.FindSpec{
 			CustomerID: &customerID,
-			Status:     &status,
+			Statuses:   []model.OrderStatus{model.Open},
 		})
 		if err == nil {
 			orderID = order.ID
@@ -51,32 +149,137 @@ func (o orderService) AddProductToOrder(ctx context.Context, customerID uuid.UUI
 		return err
 	})
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, uuid.Nil, err
 	}
 
 	price, err := o.productProvider.ActualPrice(ctx, productID)
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, uuid.Nil, err
 	}
 
 	var itemID uuid.UUID
-	return itemID, o.luow.Execute(ctx, "order_"+orderID.String(), func(provider RepositoryProvider) error {
+	return orderID, itemID, o.luow.Execute(ctx, orderLockName(orderID), func(provider RepositoryProvider) error {
 		domainService := o.domainService(ctx, provider.OrderRepository(ctx))
 		itemID, err = domainService.AddItem(orderID, productID, price)
 		return err
 	})
 }
 
+func (o orderService) ChangeItemQuantity(ctx context.Context, orderID uuid.UUID, itemID uuid.UUID, quantity int) error {
+	return o.luow.Execute(ctx, orderLockName(orderID), func(provider RepositoryProvider) error {
+		domainService := o.domainService(ctx, provider.OrderRepository(ctx))
+		return domainService.ChangeQuantity(orderID, itemID, quantity)
+	})
+}
+
+func (o orderService) RemoveItem(ctx context.Context, orderID uuid.UUID, itemID uuid.UUID) error {
+	return o.luow.Execute(ctx, orderLockName(orderID), func(provider RepositoryProvider) error {
+		domainService := o.domainService(ctx, provider.OrderRepository(ctx))
+		return domainService.DeleteItem(orderID, itemID)
+	})
+}
+
+func (o orderService) SetStatus(ctx context.Context, orderID uuid.UUID, status model.OrderStatus) error {
+	return o.luow.Execute(ctx, orderLockName(orderID), func(provider RepositoryProvider) error {
+		domainService := o.domainService(ctx, provider.OrderRepository(ctx))
+		return domainService.SetStatus(orderID, status)
+	})
+}
+
+func (o orderService) CancelOrder(ctx context.Context, orderID uuid.UUID, reason model.CancellationReason, actor string) error {
+	return o.luow.Execute(ctx, orderLockName(orderID), func(provider RepositoryProvider) error {
+		domainService := o.domainService(ctx, provider.OrderRepository(ctx))
+		return domainService.CancelOrder(orderID, reason, actor)
+	})
+}
+
+func (o orderService) DeleteOrder(ctx context.Context, orderID uuid.UUID) error {
+	return o.luow.Execute(ctx, orderLockName(orderID), func(provider RepositoryProvider) error {
+		domainService := o.domainService(ctx, provider.OrderRepository(ctx))
+		return domainService.DeleteOrder(orderID)
+	})
+}
+
+func (o orderService) Checkout(ctx context.Context, orderID uuid.UUID, acceptPriceChanges bool) ([]model.ItemPriceChange, error) {
+	// catalog is queried before taking the order lock, items added meanwhile make the attempt start over
+	for attempt := 1; ; attempt++ {
+		order, err := o.FindOrder(ctx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		actualPrices, err := o.actualPrices(ctx, order.Items)
+		if err != nil {
+			return nil, err
+		}
+
+		var changes []model.ItemPriceChange
+		err = o.luow.Execute(ctx, orderLockName(orderID), func(provider RepositoryProvider) error {
+			order, err := provider.OrderRepository(ctx).Find(model.FindSpec{OrderID: &orderID})
+			if err != nil {
+				return err
+			}
+			err = checkOrderVersion(ctx, order)
+			if err != nil {
+				return err
+			}
+			for _, item := range order.Items {
+				if _, ok := actualPrices[item.ProductID]; !ok {
+					return errCheckoutItemsChanged
+				}
+			}
+
+			domainService := o.domainService(ctx, provider.OrderRepository(ctx))
+			changes, err = domainService.Checkout(orderID, actualPrices, acceptPriceChanges)
+			return err
+		})
+		if errors.Is(err, errCheckoutItemsChanged) {
+			if attempt < maxCheckoutAttempts {
+				continue
+			}
+			return nil, model.ErrConcurrentModification
+		}
+		return changes, err
+	}
+}
+
+// actualPrices queries the catalog directly, cached prices may be stale for re-validation
+func (o orderService) actualPrices(ctx context.Context, items []model.Item) (map[uuid.UUID]model.Money, error) {
+	prices := make(map[uuid.UUID]model.Money, len(items))
+	for _, item := range items {
+		if _, ok := prices[item.ProductID]; ok {
+			continue
+		}
+		price, err := o.catalogProvider.ActualPrice(ctx, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		prices[item.ProductID] = price
+	}
+	return prices, nil
+}
+
 func (o orderService) domainService(ctx context.Context, repo model.OrderRepository) service.Order {
+	if _, ok := ctx.Value(expectedVersionKey{}).(int64); ok {
+		repo = expectedVersionRepository{OrderRepository: repo, ctx: ctx}
+	}
 	return service.NewOrderService(
 		repo,
 		&domainEventDispatcher{
 			ctx:             ctx,
 			eventDispatcher: o.eventDispatcher,
 		},
+		o.orderOptions,
 	)
 }
 
+func customerLockName(customerID uuid.UUID) string {
+	return "customer_" + customerID.String()
+}
+
+func orderLockName(orderID uuid.UUID) string {
+	return "order_" + orderID.String()
+}
+
 type domainEventDispatcher struct {
 	ctx             context.Context
 	eventDispatcher outbox.EventDispatcher[service.Event]
@@ -0,0 +1,354 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+	domainservice "order/pkg/domain/service"
+)
+
+func TestCheckout(t *testing.T) {
+	ctx := context.Background()
+	productID := uuid.Must(uuid.NewV7())
+	oldPrice := mustMoney(t, 1000)
+	newPrice := mustMoney(t, 1200)
+
+	t.Run("Prices are queried from the catalog outside of the order lock", func(t *testing.T) {
+		env := newTestEnv()
+		env.cachedProducts.prices[productID] = oldPrice
+		env.catalogProducts.prices[productID] = newPrice
+		env.catalogProducts.onCall = func() {
+			require.False(t, env.luow.locked, "catalog is called under the order lock")
+		}
+		orderID, _, err := env.orderService.AddProductToOrder(ctx, uuid.Must(uuid.NewV7()), productID)
+		require.NoError(t, err)
+
+		_, err = env.orderService.Checkout(ctx, orderID, false)
+		var priceChangedErr domainservice.PriceChangedError
+		require.ErrorAs(t, err, &priceChangedErr)
+		require.Equal(t, newPrice, priceChangedErr.Changes[0].NewPrice)
+
+		changes, err := env.orderService.Checkout(ctx, orderID, true)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.Equal(t, model.Pending, env.repo.orders[orderID].Status)
+	})
+
+	t.Run("Items added while prices are queried restart checkout", func(t *testing.T) {
+		env := newTestEnv()
+		addedProductID := uuid.Must(uuid.NewV7())
+		for _, id := range []uuid.UUID{productID, addedProductID} {
+			env.cachedProducts.prices[id] = oldPrice
+			env.catalogProducts.prices[id] = oldPrice
+		}
+		customerID := uuid.Must(uuid.NewV7())
+		orderID, _, err := env.orderService.AddProductToOrder(ctx, customerID, productID)
+		require.NoError(t, err)
+
+		env.catalogProducts.onCall = func() {
+			env.catalogProducts.onCall = nil
+			_, _, err := env.orderService.AddProductToOrder(ctx, customerID, addedProductID)
+			require.NoError(t, err)
+		}
+		_, err = env.orderService.Checkout(ctx, orderID, false)
+		require.NoError(t, err)
+		require.Equal(t, 3, env.catalogProducts.calls)
+		require.Len(t, env.repo.orders[orderID].Items, 2)
+		require.Equal(t, model.Pending, env.repo.orders[orderID].Status)
+	})
+
+	t.Run("Checkout gives up when items keep changing", func(t *testing.T) {
+		env := newTestEnv()
+		env.cachedProducts.prices[productID] = oldPrice
+		env.catalogProducts.prices[productID] = oldPrice
+		customerID := uuid.Must(uuid.NewV7())
+		orderID, _, err := env.orderService.AddProductToOrder(ctx, customerID, productID)
+		require.NoError(t, err)
+
+		env.catalogProducts.onCall = func() {
+			addedProductID := uuid.Must(uuid.NewV7())
+			env.cachedProducts.prices[addedProductID] = oldPrice
+			env.catalogProducts.prices[addedProductID] = oldPrice
+			_, _, err := env.orderService.AddProductToOrder(ctx, customerID, addedProductID)
+			require.NoError(t, err)
+		}
+		_, err = env.orderService.Checkout(ctx, orderID, false)
+		require.ErrorIs(t, err, model.ErrConcurrentModification)
+		require.Equal(t, model.Open, env.repo.orders[orderID].Status)
+	})
+}
+
+func TestExpectedVersion(t *testing.T) {
+	ctx := context.Background()
+	productID := uuid.Must(uuid.NewV7())
+	env := newTestEnv()
+	env.cachedProducts.prices[productID] = mustMoney(t, 1000)
+	orderID, itemID, err := env.orderService.AddProductToOrder(ctx, uuid.Must(uuid.NewV7()), productID)
+	require.NoError(t, err)
+	version := env.repo.orders[orderID].Version
+
+	t.Run("Stale version is rejected", func(t *testing.T) {
+		err := env.orderService.ChangeItemQuantity(service.WithExpectedVersion(ctx, version-1), orderID, itemID, 2)
+		require.ErrorIs(t, err, model.ErrConcurrentModification)
+		require.Equal(t, version, env.repo.orders[orderID].Version)
+		require.Equal(t, 1, env.repo.orders[orderID].Items[0].Quantity)
+	})
+
+	t.Run("Current version is accepted without extra loading", func(t *testing.T) {
+		finds := env.repo.finds
+		err := env.orderService.ChangeItemQuantity(service.WithExpectedVersion(ctx, version), orderID, itemID, 2)
+		require.NoError(t, err)
+		require.Equal(t, finds+1, env.repo.finds)
+		require.Equal(t, version+1, env.repo.orders[orderID].Version)
+	})
+
+	t.Run("Order changed concurrently", func(t *testing.T) {
+		err := env.orderService.SetStatus(service.WithExpectedVersion(ctx, version), orderID, model.Pending)
+		require.ErrorIs(t, err, model.ErrConcurrentModification)
+		require.Equal(t, model.Open, env.repo.orders[orderID].Status)
+	})
+}
+
+func TestListOrders(t *testing.T) {
+	ctx := context.Background()
+	customerID := uuid.Must(uuid.NewV7())
+	spec := model.FindSpec{CustomerID: &customerID}
+
+	t.Run("Pages through all orders", func(t *testing.T) {
+		env := newTestEnv()
+		var created []uuid.UUID
+		for range 5 {
+			orderID, err := env.orderService.CreateOrder(ctx, customerID)
+			require.NoError(t, err)
+			created = append(created, orderID)
+		}
+		_, err := env.orderService.CreateOrder(ctx, uuid.Must(uuid.NewV7()))
+		require.NoError(t, err)
+		slices.SortFunc(created, func(a, b uuid.UUID) int {
+			return strings.Compare(a.String(), b.String())
+		})
+
+		orders, next, err := env.orderService.ListOrders(ctx, spec, model.Page{Limit: 2})
+		require.NoError(t, err)
+		require.Equal(t, created[:2], orderIDs(orders))
+		require.Equal(t, &model.Page{After: created[1], Limit: 2}, next)
+
+		orders, next, err = env.orderService.ListOrders(ctx, spec, *next)
+		require.NoError(t, err)
+		require.Equal(t, created[2:4], orderIDs(orders))
+		require.NotNil(t, next)
+
+		orders, next, err = env.orderService.ListOrders(ctx, spec, *next)
+		require.NoError(t, err)
+		require.Equal(t, created[4:], orderIDs(orders))
+		require.Nil(t, next)
+	})
+
+	t.Run("Full last page has no next page", func(t *testing.T) {
+		env := newTestEnv()
+		for range 2 {
+			_, err := env.orderService.CreateOrder(ctx, customerID)
+			require.NoError(t, err)
+		}
+
+		orders, next, err := env.orderService.ListOrders(ctx, spec, model.Page{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, orders, 2)
+		require.Nil(t, next)
+	})
+
+	t.Run("Empty page", func(t *testing.T) {
+		env := newTestEnv()
+
+		orders, next, err := env.orderService.ListOrders(ctx, spec, model.Page{Limit: 2})
+		require.NoError(t, err)
+		require.Empty(t, orders)
+		require.Nil(t, next)
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		env := newTestEnv()
+
+		_, _, err := env.orderService.ListOrders(ctx, spec, model.Page{})
+		require.ErrorIs(t, err, service.ErrInvalidPageLimit)
+	})
+}
+
+func orderIDs(orders []model.Order) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(orders))
+	for _, order := range orders {
+		ids = append(ids, order.ID)
+	}
+	return ids
+}
+
+func TestNewOrderService(t *testing.T) {
+	env := newTestEnv()
+	uow := &mockOrderUnitOfWork{repo: env.repo}
+	require.PanicsWithValue(t, "catalog provider cannot be nil", func() {
+		service.NewOrderService(uow, env.luow, env.events, env.cachedProducts, nil, domainservice.Options{})
+	})
+	require.PanicsWithValue(t, "unit of work cannot be nil", func() {
+		service.NewOrderService(nil, env.luow, env.events, env.cachedProducts, env.catalogProducts, domainservice.Options{})
+	})
+}
+
+type testEnv struct {
+	repo            *memoryOrderRepository
+	luow            *mockLockableUnitOfWork
+	cachedProducts  *mockProductProvider
+	catalogProducts *mockProductProvider
+	events          *mockOutboxDispatcher
+	orderService    service.OrderService
+}
+
+func newTestEnv() *testEnv {
+	env := &testEnv{
+		repo:            &memoryOrderRepository{orders: map[uuid.UUID]model.Order{}},
+		cachedProducts:  &mockProductProvider{prices: map[uuid.UUID]model.Money{}},
+		catalogProducts: &mockProductProvider{prices: map[uuid.UUID]model.Money{}},
+		events:          &mockOutboxDispatcher{},
+	}
+	uow := &mockOrderUnitOfWork{repo: env.repo}
+	env.luow = &mockLockableUnitOfWork{uow: uow}
+	env.orderService = service.NewOrderService(
+		uow,
+		env.luow,
+		env.events,
+		env.cachedProducts,
+		env.catalogProducts,
+		domainservice.Options{},
+	)
+	return env
+}
+
+func mustMoney(t *testing.T, amount int64) model.Money {
+	t.Helper()
+	money, err := model.NewMoney(amount, "RUB")
+	require.NoError(t, err)
+	return money
+}
+
+type mockOrderUnitOfWork struct {
+	repo *memoryOrderRepository
+}
+
+func (m *mockOrderUnitOfWork) Execute(_ context.Context, f func(provider service.RepositoryProvider) error) error {
+	return f(m)
+}
+
+func (m *mockOrderUnitOfWork) OrderRepository(context.Context) model.OrderRepository {
+	return m.repo
+}
+
+func (m *mockOrderUnitOfWork) IdempotencyRepository(context.Context) service.IdempotencyRepository {
+	panic("unexpected idempotency repository")
+}
+
+type mockLockableUnitOfWork struct {
+	uow    *mockOrderUnitOfWork
+	locked bool
+}
+
+func (m *mockLockableUnitOfWork) Execute(ctx context.Context, _ string, f func(provider service.RepositoryProvider) error) error {
+	m.locked = true
+	defer func() { m.locked = false }()
+	return m.uow.Execute(ctx, f)
+}
+
+// memoryOrderRepository returns copies of orders like a database would
+type memoryOrderRepository struct {
+	orders map[uuid.UUID]model.Order
+	finds  int
+}
+
+func (m *memoryOrderRepository) NextID() (uuid.UUID, error) {
+	return uuid.NewV7()
+}
+
+func (m *memoryOrderRepository) Store(order *model.Order) error {
+	if stored, ok := m.orders[order.ID]; ok && stored.Version != order.Version {
+		return model.ErrConcurrentModification
+	}
+	order.Version++
+	m.orders[order.ID] = cloneOrder(*order)
+	return nil
+}
+
+func (m *memoryOrderRepository) Find(spec model.FindSpec) (*model.Order, error) {
+	m.finds++
+	for _, order := range m.orders {
+		if matchesSpec(order, spec) {
+			order = cloneOrder(order)
+			return &order, nil
+		}
+	}
+	return nil, model.ErrOrderNotFound
+}
+
+func (m *memoryOrderRepository) List(spec model.FindSpec, page model.Page) ([]model.Order, error) {
+	var orders []model.Order
+	for _, order := range m.orders {
+		if matchesSpec(order, spec) && order.ID.String() > page.After.String() {
+			orders = append(orders, cloneOrder(order))
+		}
+	}
+	slices.SortFunc(orders, func(a, b model.Order) int {
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	if len(orders) > page.Limit {
+		orders = orders[:page.Limit]
+	}
+	return orders, nil
+}
+
+func (m *memoryOrderRepository) Delete(id uuid.UUID) error {
+	delete(m.orders, id)
+	return nil
+}
+
+func matchesSpec(order model.Order, spec model.FindSpec) bool {
+	return (spec.OrderID == nil || order.ID == *spec.OrderID) &&
+		(spec.CustomerID == nil || order.CustomerID == *spec.CustomerID) &&
+		(len(spec.Statuses) == 0 || slices.Contains(spec.Statuses, order.Status))
+}
+
+func cloneOrder(order model.Order) model.Order {
+	order.Items = slices.Clone(order.Items)
+	order.Totals = slices.Clone(order.Totals)
+	return order
+}
+
+type mockProductProvider struct {
+	prices map[uuid.UUID]model.Money
+	calls  int
+	onCall func()
+}
+
+func (m *mockProductProvider) ActualPrice(_ context.Context, productID uuid.UUID) (model.Money, error) {
+	m.calls++
+	if m.onCall != nil {
+		m.onCall()
+	}
+	price, ok := m.prices[productID]
+	if !ok {
+		return model.Money{}, model.ErrProductNotFound
+	}
+	return price, nil
+}
+
+type mockOutboxDispatcher struct {
+	events []domainservice.Event
+}
+
+func (m *mockOutboxDispatcher) Dispatch(_ context.Context, event domainservice.Event) error {
+	m.events = append(m.events, event)
+	return nil
+}
@@ -2,12 +2,14 @@ package service
 
 import (
 	"context"
+	"sync/atomic"
 
 	"order/pkg/domain/model"
 )
 
 type RepositoryProvider interface {
 	OrderRepository(ctx context.Context) model.OrderRepository
+	IdempotencyRepository(ctx context.Context) IdempotencyRepository
 }
 
 type LockableUnitOfWork interface {
@@ -17,3 +19,42 @@ type LockableUnitOfWork interface {
 type UnitOfWork interface {
 	Execute(ctx context.Context, f func(provider RepositoryProvider) error) error
 }
+
+type sharedTransactionKey struct{}
+
+type sharedTransaction struct {
+	ctx     context.Context
+	running atomic.Bool
+}
+
+// WithSharedTransaction makes units of work executed with the returned context or contexts derived from it
+// run in one transaction, it is committed or rolled back by the outermost of them.
+// Named locks are released when the outermost lockable unit of work finishes, so nested units keep their locks
+// until the commit only when the outermost unit is lockable
+func WithSharedTransaction(ctx context.Context) context.Context {
+	shared := &sharedTransaction{}
+	shared.ctx = context.WithValue(ctx, sharedTransactionKey{}, shared)
+	return shared.ctx
+}
+
+// TransactionContext returns the context which identifies the transaction of ctx:
+// the one created by WithSharedTransaction or ctx itself
+func TransactionContext(ctx context.Context) context.Context {
+	if shared, ok := ctx.Value(sharedTransactionKey{}).(*sharedTransaction); ok {
+		return shared.ctx
+	}
+	return ctx
+}
+
+// EnterTransaction reports whether ctx belongs to a shared transaction which is already run by a unit of work.
+// Otherwise the caller runs the transaction and calls exit when it is finished
+func EnterTransaction(ctx context.Context) (nested bool, exit func()) {
+	shared, ok := ctx.Value(sharedTransactionKey{}).(*sharedTransaction)
+	if !ok {
+		return false, func() {}
+	}
+	if shared.running.Swap(true) {
+		return true, func() {}
+	}
+	return false, func() { shared.running.Store(false) }
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+
+	"order/pkg/domain/model"
+)
+
+type expectedVersionKey struct{}
+
+// WithExpectedVersion makes order mutations fail with model.ErrConcurrentModification
+// unless the order still has the given version, like HTTP If-Match
+func WithExpectedVersion(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, expectedVersionKey{}, version)
+}
+
+func checkOrderVersion(ctx context.Context, order *model.Order) error {
+	version, ok := ctx.Value(expectedVersionKey{}).(int64)
+	if ok && order.Version != version {
+		return model.ErrConcurrentModification
+	}
+	return nil
+}
+
+// expectedVersionRepository checks the expected version of ctx against orders as they are loaded
+type expectedVersionRepository struct {
+	model.OrderRepository
+	ctx context.Context
+}
+
+func (r expectedVersionRepository) Find(spec model.FindSpec) (*model.Order, error) {
+	order, err := r.OrderRepository.Find(spec)
+	if err != nil {
+		return nil, err
+	}
+	err = checkOrderVersion(r.ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
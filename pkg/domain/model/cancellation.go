@@ -0,0 +1,34 @@
+package model
+
+import "fmt"
+
+type CancellationReason int
+
+const (
+	CustomerRequest CancellationReason = iota
+	OutOfStock
+	PaymentFailed
+	FraudSuspected
+	OtherReason
+)
+
+func (r CancellationReason) String() string {
+	switch r {
+	case CustomerRequest:
+		return "CustomerRequest"
+	case OutOfStock:
+		return "OutOfStock"
+	case PaymentFailed:
+		return "PaymentFailed"
+	case FraudSuspected:
+		return "FraudSuspected"
+	case OtherReason:
+		return "Other"
+	default:
+		return fmt.Sprintf("CancellationReason(%d)", int(r))
+	}
+}
+
+func (r CancellationReason) IsValid() bool {
+	return r >= CustomerRequest && r <= OtherReason
+}
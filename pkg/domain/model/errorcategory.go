@@ -0,0 +1,117 @@
+package model
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrorCategory tells what kind of failure an error is, so that transports can report it without knowing every error
+type ErrorCategory int
+
+const (
+	UncategorizedError ErrorCategory = iota
+	InvalidArgumentError
+	NotFoundError
+	FailedPreconditionError
+	// ConflictError means that the entity already exists
+	ConflictError
+	// AbortedError means that the operation lost a concurrency conflict and may be retried
+	AbortedError
+	UnavailableError
+)
+
+// ErrorOption adds optional details to errors declared with NewError
+type ErrorOption func(e *categorizedError)
+
+// WithField names the request field holding the value which the error is about
+func WithField(field string) ErrorOption {
+	return func(e *categorizedError) {
+		e.field = field
+	}
+}
+
+// NewError declares a sentinel error of the category, it is compared with errors.Is as errors.New.
+// Reason is a stable UPPER_SNAKE_CASE identifier for clients, unlike text it must not change
+func NewError(category ErrorCategory, reason, text string, opts ...ErrorOption) error {
+	err := &categorizedError{
+		text:     text,
+		reason:   reason,
+		category: category,
+	}
+	for _, opt := range opts {
+		opt(err)
+	}
+	return err
+}
+
+// FieldError is an error declared WithField
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+// FieldErrorsOf returns errors declared WithField in the tree of err, joined errors are searched depth first.
+// Each field is returned once, with the first error about it
+func FieldErrorsOf(err error) []FieldError {
+	var fieldErrors []FieldError
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+			return
+		case *categorizedError:
+			if e.field != "" && !slices.ContainsFunc(fieldErrors, func(f FieldError) bool { return f.Field == e.field }) {
+				fieldErrors = append(fieldErrors, FieldError{Field: e.field, Err: e})
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		case interface{ Unwrap() []error }:
+			for _, joined := range e.Unwrap() {
+				walk(joined)
+			}
+		}
+	}
+	walk(err)
+	return fieldErrors
+}
+
+// CategoryOf returns category of the first categorized error in the tree of err, joined errors are searched depth first
+func CategoryOf(err error) ErrorCategory {
+	var categorized interface {
+		Category() ErrorCategory
+	}
+	if errors.As(err, &categorized) {
+		return categorized.Category()
+	}
+	return UncategorizedError
+}
+
+// ReasonOf returns reason of the first categorized error in the tree of err or empty string
+func ReasonOf(err error) string {
+	var categorized interface {
+		Reason() string
+	}
+	if errors.As(err, &categorized) {
+		return categorized.Reason()
+	}
+	return ""
+}
+
+type categorizedError struct {
+	text     string
+	reason   string
+	category ErrorCategory
+	field    string
+}
+
+func (e *categorizedError) Error() string {
+	return e.text
+}
+
+func (e *categorizedError) Category() ErrorCategory {
+	return e.category
+}
+
+func (e *categorizedError) Reason() string {
+	return e.reason
+}
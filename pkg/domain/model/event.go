@@ -12,11 +12,72 @@ func (e OrderCreated) Type() string {
 }
 
 type OrderItemChanged struct {
-	OrderID      uuid.UUID
-	AddedItems   []uuid.UUID
-	RemovedItems []uuid.UUID
+	OrderID         uuid.UUID
+	AddedItems      []uuid.UUID
+	RemovedItems    []uuid.UUID
+	QuantityChanges []ItemQuantityChange
+}
+
+// ItemQuantityChange is a signed change of item quantity, added and removed items have it too
+type ItemQuantityChange struct {
+	ItemID    uuid.UUID
+	ProductID uuid.UUID
+	Delta     int
 }
 
 func (e OrderItemChanged) Type() string {
 	return "OrderItemChanged"
 }
+
+type OrderStatusChanged struct {
+	OrderID   uuid.UUID
+	OldStatus OrderStatus
+	NewStatus OrderStatus
+}
+
+func (e OrderStatusChanged) Type() string {
+	return "OrderStatusChanged"
+}
+
+type OrderDeleted struct {
+	OrderID    uuid.UUID
+	CustomerID uuid.UUID
+}
+
+func (e OrderDeleted) Type() string {
+	return "OrderDeleted"
+}
+
+// OrderRepriced is dispatched when item prices are updated to actual catalog prices
+type OrderRepriced struct {
+	OrderID      uuid.UUID
+	PriceChanges []ItemPriceChange
+}
+
+// ItemPriceChange is a difference between captured item price and actual price of the product
+type ItemPriceChange struct {
+	ItemID    uuid.UUID
+	ProductID uuid.UUID
+	OldPrice  Money
+	NewPrice  Money
+}
+
+func (e OrderRepriced) Type() string {
+	return "OrderRepriced"
+}
+
+// OrderCancelled carries order contents, so that reserved stock and payments can be compensated
+type OrderCancelled struct {
+	OrderID    uuid.UUID
+	CustomerID uuid.UUID
+	OldStatus  OrderStatus
+	Reason     CancellationReason
+	// Actor identifies who cancelled the order, e.g. a user or a service
+	Actor  string
+	Items  []Item
+	Totals []OrderTotal
+}
+
+func (e OrderCancelled) Type() string {
+	return "OrderCancelled"
+}
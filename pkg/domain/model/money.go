@@ -0,0 +1,104 @@
+package model
+
+import "math"
+
+var (
+	ErrInvalidCurrency  = NewError(InvalidArgumentError, "INVALID_CURRENCY", "invalid currency")
+	ErrCurrencyMismatch = NewError(FailedPreconditionError, "CURRENCY_MISMATCH", "currency mismatch")
+	ErrMoneyOverflow    = NewError(InvalidArgumentError, "MONEY_OVERFLOW", "money overflow")
+)
+
+// Money is an exact amount in minor units (e.g. cents) of an ISO 4217 currency.
+// Zero value has no currency and acts as an identity for Add
+type Money struct {
+	amount   int64
+	currency string
+}
+
+func NewMoney(amount int64, currency string) (Money, error) {
+	if !isCurrencyCode(currency) {
+		return Money{}, ErrInvalidCurrency
+	}
+	return Money{
+		amount:   amount,
+		currency: currency,
+	}, nil
+}
+
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+func (m Money) Currency() string {
+	return m.currency
+}
+
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.currency == "" {
+		return other, nil
+	}
+	if other.currency == "" {
+		return m, nil
+	}
+	if m.currency != other.currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	if (other.amount > 0 && m.amount > math.MaxInt64-other.amount) ||
+		(other.amount < 0 && m.amount < math.MinInt64-other.amount) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return Money{amount: m.amount + other.amount, currency: m.currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if other.amount == math.MinInt64 {
+		return Money{}, ErrMoneyOverflow
+	}
+	return m.Add(Money{amount: -other.amount, currency: other.currency})
+}
+
+func (m Money) Multiply(n int64) (Money, error) {
+	if m.amount != 0 && n != 0 {
+		result := m.amount * n
+		if result/n != m.amount || (m.amount == -1 && n == math.MinInt64) || (n == -1 && m.amount == math.MinInt64) {
+			return Money{}, ErrMoneyOverflow
+		}
+		return Money{amount: result, currency: m.currency}, nil
+	}
+	return Money{currency: m.currency}, nil
+}
+
+// ApplyRate returns the given rate of money in basis points (1/100 of percent) rounded half away from zero
+func (m Money) ApplyRate(basisPoints int64) (Money, error) {
+	const basisPointsInOne = 10000
+	product, err := m.Multiply(basisPoints)
+	if err != nil {
+		return Money{}, err
+	}
+
+	amount := product.amount / basisPointsInOne
+	remainder := product.amount % basisPointsInOne
+	switch {
+	case remainder >= basisPointsInOne/2:
+		amount++
+	case remainder <= -basisPointsInOne/2:
+		amount--
+	}
+	return Money{amount: amount, currency: m.currency}, nil
+}
+
+func isCurrencyCode(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, c := range currency {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
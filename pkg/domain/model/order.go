@@ -1,13 +1,18 @@
 package model
 
 import (
-	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-var ErrOrderNotFound = errors.New("order not found")
+var (
+	ErrOrderNotFound = NewError(NotFoundError, "ORDER_NOT_FOUND", "order not found")
+	ErrItemNotFound  = NewError(NotFoundError, "ITEM_NOT_FOUND", "item not found")
+	// ErrConcurrentModification is returned when an order was changed after it had been loaded
+	ErrConcurrentModification = NewError(AbortedError, "CONCURRENT_MODIFICATION", "order was modified concurrently")
+)
 
 type OrderStatus int
 
@@ -18,32 +23,65 @@ const (
 	Cancelled
 )
 
+func (s OrderStatus) String() string {
+	switch s {
+	case Open:
+		return "Open"
+	case Pending:
+		return "Pending"
+	case Paid:
+		return "Paid"
+	case Cancelled:
+		return "Cancelled"
+	default:
+		return fmt.Sprintf("OrderStatus(%d)", int(s))
+	}
+}
+
 type Order struct {
 	ID         uuid.UUID
 	CustomerID uuid.UUID
 	Status     OrderStatus
-	Items      []Item
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	DeletedAt  *time.Time
+	// Version is incremented on every Store, zero means the order has not been stored yet
+	Version   int64
+	Items     []Item
+	Totals    []OrderTotal
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time
 }
 
 type Item struct {
+	ID        uuid.UUID
 	OrderID   uuid.UUID
 	ProductID uuid.UUID
-	Price     float64
+	Price     Money
+	Quantity  int
 }
 
+// FindSpec filters orders, unset fields match any order
 type FindSpec struct {
-	OrderID        *uuid.UUID
-	CustomerID     *uuid.UUID
-	Status         *OrderStatus
+	OrderID    *uuid.UUID
+	CustomerID *uuid.UUID
+	// Statuses matches orders in any of the statuses
+	Statuses []OrderStatus
+	// CreatedFrom is inclusive and CreatedTo is exclusive bound of CreatedAt
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
 	IncludeDeleted bool
 }
 
+// Page selects at most Limit orders ordered by ID, starting after the order with ID After.
+// Order IDs are UUIDv7 so pages are stable and follow creation order
+type Page struct {
+	After uuid.UUID
+	Limit int
+}
+
 type OrderRepository interface {
 	NextID() (uuid.UUID, error)
 	Store(order *Order) error
 	Find(spec FindSpec) (*Order, error)
+	List(spec FindSpec, page Page) ([]Order, error)
 	Delete(id uuid.UUID) error
 }
@@ -0,0 +1,6 @@
+package model
+
+var (
+	ErrProductNotFound    = NewError(NotFoundError, "PRODUCT_NOT_FOUND", "product not found")
+	ErrProductUnavailable = NewError(UnavailableError, "PRODUCT_UNAVAILABLE", "product catalog unavailable")
+)
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"slices"
+	"strings"
+)
+
+// PricingRules are order level adjustments applied to every currency subtotal, rates are in basis points
+type PricingRules struct {
+	DiscountRate int64
+	TaxRate      int64
+}
+
+// OrderTotal aggregates order items priced in the same currency
+type OrderTotal struct {
+	Subtotal Money
+	Discount Money
+	Tax      Money
+	Total    Money
+}
+
+func (t OrderTotal) Currency() string {
+	return t.Subtotal.Currency()
+}
+
+// RecalculateTotals rebuilds order totals from items, one total per currency ordered by currency code.
+// Tax is charged on subtotal after discount
+func (o *Order) RecalculateTotals(rules PricingRules) error {
+	subtotals := make(map[string]Money)
+	for _, item := range o.Items {
+		lineTotal, err := item.Price.Multiply(int64(item.Quantity))
+		if err != nil {
+			return err
+		}
+		subtotal, err := subtotals[item.Price.Currency()].Add(lineTotal)
+		if err != nil {
+			return err
+		}
+		subtotals[item.Price.Currency()] = subtotal
+	}
+
+	totals := make([]OrderTotal, 0, len(subtotals))
+	for _, subtotal := range subtotals {
+		total, err := calculateTotal(subtotal, rules)
+		if err != nil {
+			return err
+		}
+		totals = append(totals, total)
+	}
+	slices.SortFunc(totals, func(a, b OrderTotal) int {
+		return strings.Compare(a.Currency(), b.Currency())
+	})
+
+	o.Totals = totals
+	return nil
+}
+
+func calculateTotal(subtotal Money, rules PricingRules) (OrderTotal, error) {
+	discount, err := subtotal.ApplyRate(rules.DiscountRate)
+	if err != nil {
+		return OrderTotal{}, err
+	}
+	taxable, err := subtotal.Sub(discount)
+	if err != nil {
+		return OrderTotal{}, err
+	}
+	tax, err := taxable.ApplyRate(rules.TaxRate)
+	if err != nil {
+		return OrderTotal{}, err
+	}
+	total, err := taxable.Add(tax)
+	if err != nil {
+		return OrderTotal{}, err
+	}
+
+	return OrderTotal{
+		Subtotal: subtotal,
+		Discount: discount,
+		Tax:      tax,
+		Total:    total,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"order/pkg/domain/model"
+)
+
+var (
+	ErrInvalidCancellationReason = model.NewError(model.InvalidArgumentError, "INVALID_CANCELLATION_REASON", "invalid cancellation reason", model.WithField("reason"))
+	ErrEmptyActor                = model.NewError(model.InvalidArgumentError, "EMPTY_ACTOR", "actor is required", model.WithField("actor"))
+	// ErrCancellationWithoutReason is returned by SetStatus, orders are cancelled only by CancelOrder
+	ErrCancellationWithoutReason = model.NewError(model.InvalidArgumentError, "CANCELLATION_WITHOUT_REASON", "order must be cancelled with a reason", model.WithField("status"))
+)
+
+// CancelOrder cancels an order that has not been paid yet. Besides OrderStatusChanged
+// it dispatches OrderCancelled with order contents for compensation
+func (o orderService) CancelOrder(orderID uuid.UUID, reason model.CancellationReason, actor string) error {
+	if !reason.IsValid() {
+		return ErrInvalidCancellationReason
+	}
+	if actor == "" {
+		return ErrEmptyActor
+	}
+
+	order, err := o.repo.Find(model.FindSpec{OrderID: &orderID})
+	if err != nil {
+		return err
+	}
+
+	oldStatus := order.Status
+	err = checkStatusTransition(oldStatus, model.Cancelled)
+	if err != nil {
+		return err
+	}
+
+	order.Status = model.Cancelled
+	order.UpdatedAt = time.Now()
+	err = o.repo.Store(order)
+	if err != nil {
+		return err
+	}
+
+	err = o.dispatcher.Dispatch(model.OrderStatusChanged{
+		OrderID:   orderID,
+		OldStatus: oldStatus,
+		NewStatus: model.Cancelled,
+	})
+	if err != nil {
+		return err
+	}
+
+	return o.dispatcher.Dispatch(model.OrderCancelled{
+		OrderID:    orderID,
+		CustomerID: order.CustomerID,
+		OldStatus:  oldStatus,
+		Reason:     reason,
+		Actor:      actor,
+		Items:      order.Items,
+		Totals:     order.Totals,
+	})
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"order/pkg/domain/model"
+)
+
+var ErrPriceChanged = model.NewError(model.FailedPreconditionError, "PRICE_CHANGED", "item prices changed")
+
+// PriceChangedError lists items whose captured price differs from the actual product price
+type PriceChangedError struct {
+	Changes []model.ItemPriceChange
+}
+
+func (e PriceChangedError) Error() string {
+	changes := make([]string, 0, len(e.Changes))
+	for _, change := range e.Changes {
+		changes = append(changes, fmt.Sprintf(
+			"%s: %d %s -> %d %s",
+			change.ItemID,
+			change.OldPrice.Amount(), change.OldPrice.Currency(),
+			change.NewPrice.Amount(), change.NewPrice.Currency(),
+		))
+	}
+	return fmt.Sprintf("%v: %s", ErrPriceChanged, strings.Join(changes, ", "))
+}
+
+func (e PriceChangedError) Unwrap() error {
+	return ErrPriceChanged
+}
+
+func (o orderService) Checkout(orderID uuid.UUID, actualPrices map[uuid.UUID]model.Money, reprice bool) ([]model.ItemPriceChange, error) {
+	order, err := o.findOpenOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []model.ItemPriceChange
+	for _, item := range order.Items {
+		price, ok := actualPrices[item.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", model.ErrProductNotFound, item.ProductID)
+		}
+		if price != item.Price {
+			changes = append(changes, model.ItemPriceChange{
+				ItemID:    item.ID,
+				ProductID: item.ProductID,
+				OldPrice:  item.Price,
+				NewPrice:  price,
+			})
+		}
+	}
+	if len(changes) > 0 && !reprice {
+		return changes, PriceChangedError{Changes: changes}
+	}
+
+	if len(changes) > 0 {
+		for i, item := range order.Items {
+			order.Items[i].Price = actualPrices[item.ProductID]
+		}
+		err = order.RecalculateTotals(o.options.PricingRules)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	order.Status = model.Pending
+	order.UpdatedAt = time.Now()
+	err = o.repo.Store(order)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(changes) > 0 {
+		err = o.dispatcher.Dispatch(model.OrderRepriced{
+			OrderID:      orderID,
+			PriceChanges: changes,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return changes, o.dispatcher.Dispatch(model.OrderStatusChanged{
+		OrderID:   orderID,
+		OldStatus: model.Open,
+		NewStatus: model.Pending,
+	})
+}
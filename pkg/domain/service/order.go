@@ -1,7 +1,7 @@
 package service
 
 import (
-	"errors"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,7 +10,9 @@ import (
 )
 
 var (
-	ErrInvalidOrderStatus = errors.New("invalid order status")
+	ErrInvalidOrderStatus = model.NewError(model.FailedPreconditionError, "INVALID_ORDER_STATUS", "invalid order status")
+	ErrInvalidQuantity    = model.NewError(model.InvalidArgumentError, "INVALID_QUANTITY", "invalid item quantity", model.WithField("quantity"))
+	ErrPaidOrderDeletion  = model.NewError(model.FailedPreconditionError, "PAID_ORDER_DELETION", "paid order can not be deleted")
 )
 
 type Event interface {
@@ -24,22 +26,37 @@ type EventDispatcher interface {
 type Order interface {
 	CreateOrder(customerID uuid.UUID) (uuid.UUID, error)
 	DeleteOrder(orderID uuid.UUID) error
+	// SetStatus moves the order to status, except Cancelled which requires CancelOrder
 	SetStatus(orderID uuid.UUID, status model.OrderStatus) error
+	CancelOrder(orderID uuid.UUID, reason model.CancellationReason, actor string) error
 
-	AddItem(orderID uuid.UUID, productID uuid.UUID, price float64) (uuid.UUID, error)
+	AddItem(orderID uuid.UUID, productID uuid.UUID, price model.Money) (uuid.UUID, error)
+	ChangeQuantity(orderID uuid.UUID, itemID uuid.UUID, quantity int) error
 	DeleteItem(orderID uuid.UUID, itemID uuid.UUID) error
+
+	// Checkout compares item prices with actualPrices of products and moves the order to Pending.
+	// Changed prices are applied when reprice is set, otherwise PriceChangedError is returned and the order is kept intact
+	Checkout(orderID uuid.UUID, actualPrices map[uuid.UUID]model.Money, reprice bool) ([]model.ItemPriceChange, error)
+}
+
+type Options struct {
+	PricingRules model.PricingRules
+	// MergeDuplicateItems makes AddItem increment quantity of an item with the same product and price
+	MergeDuplicateItems bool
 }
 
-func NewOrderService(repo model.OrderRepository, dispatcher EventDispatcher) Order {
+func NewOrderService(repo model.OrderRepository, dispatcher EventDispatcher, options Options) Order {
 	return &orderService{
 		repo:       repo,
 		dispatcher: dispatcher,
+		options:    options,
 	}
 }
 
 type orderService struct {
 	repo       model.OrderRepository
 	dispatcher EventDispatcher
+	options    Options
 }
 
 func (o orderService) CreateOrder(customerID uuid.UUID) (uuid.UUID, error) {
@@ -67,23 +84,79 @@ func (o orderService) CreateOrder(customerID uuid.UUID) (uuid.UUID, error) {
 }
 
 func (o orderService) DeleteOrder(orderID uuid.UUID) error {
-	//TODO implement me
-	panic("implement me")
+	order, err := o.repo.Find(model.FindSpec{OrderID: &orderID})
+	if err != nil {
+		return err
+	}
+
+	if order.Status == model.Paid {
+		return ErrPaidOrderDeletion
+	}
+
+	err = o.repo.Delete(orderID)
+	if err != nil {
+		return err
+	}
+
+	return o.dispatcher.Dispatch(model.OrderDeleted{
+		OrderID:    orderID,
+		CustomerID: order.CustomerID,
+	})
 }
 
 func (o orderService) SetStatus(orderID uuid.UUID, status model.OrderStatus) error {
-	//TODO implement me
-	panic("implement me")
+	if status == model.Cancelled {
+		return ErrCancellationWithoutReason
+	}
+
+	order, err := o.repo.Find(model.FindSpec{OrderID: &orderID})
+	if err != nil {
+		return err
+	}
+
+	oldStatus := order.Status
+	if oldStatus == status {
+		return nil
+	}
+	err = checkStatusTransition(oldStatus, status)
+	if err != nil {
+		return err
+	}
+
+	order.Status = status
+	order.UpdatedAt = time.Now()
+	err = o.repo.Store(order)
+	if err != nil {
+		return err
+	}
+
+	return o.dispatcher.Dispatch(model.OrderStatusChanged{
+		OrderID:   orderID,
+		OldStatus: oldStatus,
+		NewStatus: status,
+	})
 }
 
-func (o orderService) AddItem(orderID uuid.UUID, productID uuid.UUID, price float64) (uuid.UUID, error) {
-	order, err := o.repo.Find(orderID)
+func (o orderService) AddItem(orderID uuid.UUID, productID uuid.UUID, price model.Money) (uuid.UUID, error) {
+	order, err := o.findOpenOrder(orderID)
 	if err != nil {
 		return uuid.Nil, err
 	}
 
-	if order.Status != model.Open {
-		return uuid.Nil, ErrInvalidOrderStatus
+	if o.options.MergeDuplicateItems {
+		i := slices.IndexFunc(order.Items, func(item model.Item) bool {
+			return item.ProductID == productID && item.Price == price
+		})
+		if i >= 0 {
+			order.Items[i].Quantity++
+			itemID := order.Items[i].ID
+			return itemID, o.storeItemsChange(order, model.OrderItemChanged{
+				OrderID: orderID,
+				QuantityChanges: []model.ItemQuantityChange{
+					{ItemID: itemID, ProductID: productID, Delta: 1},
+				},
+			})
+		}
 	}
 
 	itemID, err := o.repo.NextID()
@@ -92,21 +165,100 @@ func (o orderService) AddItem(orderID uuid.UUID, productID uuid.UUID, price floa
 	}
 	order.Items = append(order.Items, model.Item{
 		ID:        itemID,
+		OrderID:   orderID,
 		ProductID: productID,
 		Price:     price,
+		Quantity:  1,
 	})
-	err = o.repo.Store(order)
-	if err != nil {
-		return uuid.Nil, err
-	}
 
-	return itemID, o.dispatcher.Dispatch(model.OrderItemChanged{
+	return itemID, o.storeItemsChange(order, model.OrderItemChanged{
 		OrderID:    orderID,
 		AddedItems: []uuid.UUID{itemID},
+		QuantityChanges: []model.ItemQuantityChange{
+			{ItemID: itemID, ProductID: productID, Delta: 1},
+		},
+	})
+}
+
+func (o orderService) ChangeQuantity(orderID uuid.UUID, itemID uuid.UUID, quantity int) error {
+	if quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+
+	order, err := o.findOpenOrder(orderID)
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(order.Items, func(item model.Item) bool {
+		return item.ID == itemID
+	})
+	if i < 0 {
+		return model.ErrItemNotFound
+	}
+
+	delta := quantity - order.Items[i].Quantity
+	if delta == 0 {
+		return nil
+	}
+	order.Items[i].Quantity = quantity
+
+	return o.storeItemsChange(order, model.OrderItemChanged{
+		OrderID: orderID,
+		QuantityChanges: []model.ItemQuantityChange{
+			{ItemID: itemID, ProductID: order.Items[i].ProductID, Delta: delta},
+		},
 	})
 }
 
 func (o orderService) DeleteItem(orderID uuid.UUID, itemID uuid.UUID) error {
-	//TODO implement me
-	panic("implement me")
+	order, err := o.findOpenOrder(orderID)
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(order.Items, func(item model.Item) bool {
+		return item.ID == itemID
+	})
+	if i < 0 {
+		return model.ErrItemNotFound
+	}
+
+	item := order.Items[i]
+	order.Items = slices.Delete(order.Items, i, i+1)
+
+	return o.storeItemsChange(order, model.OrderItemChanged{
+		OrderID:      orderID,
+		RemovedItems: []uuid.UUID{itemID},
+		QuantityChanges: []model.ItemQuantityChange{
+			{ItemID: itemID, ProductID: item.ProductID, Delta: -item.Quantity},
+		},
+	})
+}
+
+func (o orderService) findOpenOrder(orderID uuid.UUID) (*model.Order, error) {
+	order, err := o.repo.Find(model.FindSpec{OrderID: &orderID})
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != model.Open {
+		return nil, ErrInvalidOrderStatus
+	}
+	return order, nil
+}
+
+func (o orderService) storeItemsChange(order *model.Order, event model.OrderItemChanged) error {
+	err := order.RecalculateTotals(o.options.PricingRules)
+	if err != nil {
+		return err
+	}
+
+	order.UpdatedAt = time.Now()
+	err = o.repo.Store(order)
+	if err != nil {
+		return err
+	}
+
+	return o.dispatcher.Dispatch(event)
 }
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"fmt"
+
+	"order/pkg/domain/model"
+)
+
+var ErrInvalidStatusTransition = model.NewError(model.FailedPreconditionError, "INVALID_STATUS_TRANSITION", "invalid order status transition")
+
+// StatusTransitionError describes a status change that is not allowed by the order state machine
+type StatusTransitionError struct {
+	From model.OrderStatus
+	To   model.OrderStatus
+}
+
+func (e StatusTransitionError) Error() string {
+	return fmt.Sprintf("%v: %v -> %v", ErrInvalidStatusTransition, e.From, e.To)
+}
+
+func (e StatusTransitionError) Unwrap() error {
+	return ErrInvalidStatusTransition
+}
+
+// statusTransitions lists target statuses reachable from each status, final statuses have no transitions
+var statusTransitions = map[model.OrderStatus][]model.OrderStatus{
+	model.Open:      {model.Pending, model.Cancelled},
+	model.Pending:   {model.Paid, model.Cancelled},
+	model.Paid:      {},
+	model.Cancelled: {},
+}
+
+func checkStatusTransition(from, to model.OrderStatus) error {
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return nil
+		}
+	}
+	return StatusTransitionError{From: from, To: to}
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/domain/model"
+	"order/pkg/domain/service"
+)
+
+func TestCancelOrder(t *testing.T) {
+	repo := &mockOrderRepository{
+		store: map[uuid.UUID]*model.Order{},
+	}
+	eventDispatcher := &mockEventDispatcher{}
+
+	orderService := service.NewOrderService(repo, eventDispatcher, service.Options{})
+
+	t.Run("Cancel pending order", func(t *testing.T) {
+		customerID := uuid.Must(uuid.NewV7())
+		orderID, err := orderService.CreateOrder(customerID)
+		require.NoError(t, err)
+		_, err = orderService.AddItem(orderID, uuid.Must(uuid.NewV7()), mustMoney(t, 100, "USD"))
+		require.NoError(t, err)
+		require.NoError(t, orderService.SetStatus(orderID, model.Pending))
+
+		require.NoError(t, orderService.CancelOrder(orderID, model.PaymentFailed, "payment"))
+		order := repo.store[orderID]
+		require.Equal(t, model.Cancelled, order.Status)
+
+		events := eventDispatcher.events[len(eventDispatcher.events)-2:]
+		require.Equal(t, model.OrderStatusChanged{
+			OrderID:   orderID,
+			OldStatus: model.Pending,
+			NewStatus: model.Cancelled,
+		}, events[0])
+		require.Equal(t, model.OrderCancelled{
+			OrderID:    orderID,
+			CustomerID: customerID,
+			OldStatus:  model.Pending,
+			Reason:     model.PaymentFailed,
+			Actor:      "payment",
+			Items:      order.Items,
+			Totals:     order.Totals,
+		}, events[1])
+	})
+
+	t.Run("Paid and cancelled orders cannot be cancelled", func(t *testing.T) {
+		orderID, err := orderService.CreateOrder(uuid.Must(uuid.NewV7()))
+		require.NoError(t, err)
+		require.NoError(t, orderService.CancelOrder(orderID, model.CustomerRequest, "customer"))
+		require.ErrorIs(t, orderService.CancelOrder(orderID, model.CustomerRequest, "customer"), service.ErrInvalidStatusTransition)
+
+		orderID, err = orderService.CreateOrder(uuid.Must(uuid.NewV7()))
+		require.NoError(t, err)
+		require.NoError(t, orderService.SetStatus(orderID, model.Pending))
+		require.NoError(t, orderService.SetStatus(orderID, model.Paid))
+
+		eventsCount := len(eventDispatcher.events)
+		require.ErrorIs(t, orderService.CancelOrder(orderID, model.CustomerRequest, "customer"), service.ErrInvalidStatusTransition)
+		require.Equal(t, model.Paid, repo.store[orderID].Status)
+		require.Len(t, eventDispatcher.events, eventsCount)
+	})
+
+	t.Run("Invalid cancellation", func(t *testing.T) {
+		orderID, err := orderService.CreateOrder(uuid.Must(uuid.NewV7()))
+		require.NoError(t, err)
+
+		require.ErrorIs(t, orderService.CancelOrder(orderID, model.CancellationReason(100), "customer"), service.ErrInvalidCancellationReason)
+		require.ErrorIs(t, orderService.CancelOrder(orderID, model.CustomerRequest, ""), service.ErrEmptyActor)
+		require.Equal(t, model.Open, repo.store[orderID].Status)
+	})
+
+	t.Run("SetStatus does not cancel orders", func(t *testing.T) {
+		orderID, err := orderService.CreateOrder(uuid.Must(uuid.NewV7()))
+		require.NoError(t, err)
+
+		eventsCount := len(eventDispatcher.events)
+		require.ErrorIs(t, orderService.SetStatus(orderID, model.Cancelled), service.ErrCancellationWithoutReason)
+		require.Equal(t, model.Open, repo.store[orderID].Status)
+		require.Len(t, eventDispatcher.events, eventsCount)
+	})
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/domain/model"
+	"order/pkg/domain/service"
+)
+
+func TestCheckout(t *testing.T) {
+	repo := &mockOrderRepository{
+		store: map[uuid.UUID]*model.Order{},
+	}
+	eventDispatcher := &mockEventDispatcher{}
+
+	orderService := service.NewOrderService(repo, eventDispatcher, service.Options{})
+
+	newOrder := func(t *testing.T) (orderID, itemID, productID uuid.UUID) {
+		t.Helper()
+		orderID, err := orderService.CreateOrder(uuid.Must(uuid.NewV7()))
+		require.NoError(t, err)
+		productID = uuid.Must(uuid.NewV7())
+		itemID, err = orderService.AddItem(orderID, productID, mustMoney(t, 100, "USD"))
+		require.NoError(t, err)
+		return orderID, itemID, productID
+	}
+
+	t.Run("Unchanged prices", func(t *testing.T) {
+		orderID, _, productID := newOrder(t)
+
+		changes, err := orderService.Checkout(orderID, map[uuid.UUID]model.Money{productID: mustMoney(t, 100, "USD")}, false)
+		require.NoError(t, err)
+		require.Empty(t, changes)
+		require.Equal(t, model.Pending, repo.store[orderID].Status)
+		require.Equal(t, model.OrderStatusChanged{
+			OrderID:   orderID,
+			OldStatus: model.Open,
+			NewStatus: model.Pending,
+		}, eventDispatcher.events[len(eventDispatcher.events)-1])
+	})
+
+	t.Run("Changed prices are rejected", func(t *testing.T) {
+		orderID, itemID, productID := newOrder(t)
+		eventsCount := len(eventDispatcher.events)
+
+		changes, err := orderService.Checkout(orderID, map[uuid.UUID]model.Money{productID: mustMoney(t, 120, "USD")}, false)
+		require.ErrorIs(t, err, service.ErrPriceChanged)
+
+		var priceErr service.PriceChangedError
+		require.ErrorAs(t, err, &priceErr)
+		expectedChanges := []model.ItemPriceChange{{
+			ItemID:    itemID,
+			ProductID: productID,
+			OldPrice:  mustMoney(t, 100, "USD"),
+			NewPrice:  mustMoney(t, 120, "USD"),
+		}}
+		require.Equal(t, expectedChanges, priceErr.Changes)
+		require.Equal(t, expectedChanges, changes)
+		require.Equal(t, model.Open, repo.store[orderID].Status)
+		require.Equal(t, mustMoney(t, 100, "USD"), repo.store[orderID].Items[0].Price)
+		require.Len(t, eventDispatcher.events, eventsCount)
+	})
+
+	t.Run("Changed prices are applied", func(t *testing.T) {
+		orderID, itemID, productID := newOrder(t)
+
+		changes, err := orderService.Checkout(orderID, map[uuid.UUID]model.Money{productID: mustMoney(t, 120, "USD")}, true)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.Equal(t, model.Pending, repo.store[orderID].Status)
+		require.Equal(t, mustMoney(t, 120, "USD"), repo.store[orderID].Items[0].Price)
+		require.Equal(t, mustMoney(t, 120, "USD"), repo.store[orderID].Totals[0].Total)
+
+		events := eventDispatcher.events[len(eventDispatcher.events)-2:]
+		require.Equal(t, model.OrderRepriced{
+			OrderID: orderID,
+			PriceChanges: []model.ItemPriceChange{{
+				ItemID:    itemID,
+				ProductID: productID,
+				OldPrice:  mustMoney(t, 100, "USD"),
+				NewPrice:  mustMoney(t, 120, "USD"),
+			}},
+		}, events[0])
+		require.Equal(t, model.OrderStatusChanged{}.Type(), events[1].Type())
+	})
+
+	t.Run("Unknown product price", func(t *testing.T) {
+		orderID, _, _ := newOrder(t)
+
+		_, err := orderService.Checkout(orderID, map[uuid.UUID]model.Money{}, true)
+		require.ErrorIs(t, err, model.ErrProductNotFound)
+		require.Equal(t, model.Open, repo.store[orderID].Status)
+	})
+
+	t.Run("Only open order can be checked out", func(t *testing.T) {
+		orderID, _, productID := newOrder(t)
+		prices := map[uuid.UUID]model.Money{productID: mustMoney(t, 100, "USD")}
+		_, err := orderService.Checkout(orderID, prices, false)
+		require.NoError(t, err)
+
+		_, err = orderService.Checkout(orderID, prices, false)
+		require.ErrorIs(t, err, service.ErrInvalidOrderStatus)
+	})
+}
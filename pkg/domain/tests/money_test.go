@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/domain/model"
+)
+
+func TestMoney(t *testing.T) {
+	t.Run("Invalid currency", func(t *testing.T) {
+		_, err := model.NewMoney(100, "usd")
+		require.ErrorIs(t, err, model.ErrInvalidCurrency)
+	})
+
+	t.Run("Add and subtract", func(t *testing.T) {
+		a := mustMoney(t, 1010, "USD")
+		b := mustMoney(t, 20, "USD")
+
+		sum, err := a.Add(b)
+		require.NoError(t, err)
+		require.Equal(t, mustMoney(t, 1030, "USD"), sum)
+
+		diff, err := a.Sub(b)
+		require.NoError(t, err)
+		require.Equal(t, mustMoney(t, 990, "USD"), diff)
+
+		sum, err = model.Money{}.Add(a)
+		require.NoError(t, err)
+		require.Equal(t, a, sum)
+	})
+
+	t.Run("Currencies are not mixed", func(t *testing.T) {
+		_, err := mustMoney(t, 100, "USD").Add(mustMoney(t, 100, "EUR"))
+		require.ErrorIs(t, err, model.ErrCurrencyMismatch)
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+		_, err := mustMoney(t, math.MaxInt64, "USD").Add(mustMoney(t, 1, "USD"))
+		require.ErrorIs(t, err, model.ErrMoneyOverflow)
+
+		_, err = mustMoney(t, math.MaxInt64/2+1, "USD").Multiply(2)
+		require.ErrorIs(t, err, model.ErrMoneyOverflow)
+	})
+}
+
+func mustMoney(t *testing.T, amount int64, currency string) model.Money {
+	t.Helper()
+	m, err := model.NewMoney(amount, currency)
+	require.NoError(t, err)
+	return m
+}
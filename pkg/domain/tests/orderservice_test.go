@@ -1,6 +1,8 @@
 package tests
 
 import (
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,7 +19,7 @@ func TestOrderService(t *testing.T) {
 	}
 	eventDispatcher := &mockEventDispatcher{}
 
-	orderService := service.NewOrderService(repo, eventDispatcher)
+	orderService := service.NewOrderService(repo, eventDispatcher, service.Options{})
 
 	customerID := uuid.Must(uuid.NewV7())
 	t.Run("Create order", func(t *testing.T) {
@@ -25,11 +27,236 @@ func TestOrderService(t *testing.T) {
 		require.NoError(t, err)
 
 		require.NotNil(t, repo.store[orderID])
+		require.Equal(t, int64(1), repo.store[orderID].Version)
 		require.Len(t, eventDispatcher.events, 1)
 		require.Equal(t, model.OrderCreated{}.Type(), eventDispatcher.events[0].Type())
 	})
 }
 
+func TestOrderStatusTransitions(t *testing.T) {
+	repo := &mockOrderRepository{
+		store: map[uuid.UUID]*model.Order{},
+	}
+	eventDispatcher := &mockEventDispatcher{}
+
+	orderService := service.NewOrderService(repo, eventDispatcher, service.Options{})
+
+	t.Run("Checkout and pay order", func(t *testing.T) {
+		orderID, err := orderService.CreateOrder(uuid.Must(uuid.NewV7()))
+		require.NoError(t, err)
+
+		require.NoError(t, orderService.SetStatus(orderID, model.Pending))
+		require.NoError(t, orderService.SetStatus(orderID, model.Paid))
+		require.Equal(t, model.Paid, repo.store[orderID].Status)
+
+		events := eventDispatcher.events[len(eventDispatcher.events)-2:]
+		require.Equal(t, model.OrderStatusChanged{OrderID: orderID, OldStatus: model.Open, NewStatus: model.Pending}, events[0])
+		require.Equal(t, model.OrderStatusChanged{OrderID: orderID, OldStatus: model.Pending, NewStatus: model.Paid}, events[1])
+	})
+
+	t.Run("Cancelled order cannot be reopened", func(t *testing.T) {
+		orderID, err := orderService.CreateOrder(uuid.Must(uuid.NewV7()))
+		require.NoError(t, err)
+		require.NoError(t, orderService.CancelOrder(orderID, model.CustomerRequest, "customer"))
+
+		eventsCount := len(eventDispatcher.events)
+		err = orderService.SetStatus(orderID, model.Open)
+		require.ErrorIs(t, err, service.ErrInvalidStatusTransition)
+
+		var transitionErr service.StatusTransitionError
+		require.ErrorAs(t, err, &transitionErr)
+		require.Equal(t, model.Cancelled, transitionErr.From)
+		require.Equal(t, model.Open, transitionErr.To)
+		require.Equal(t, model.Cancelled, repo.store[orderID].Status)
+		require.Len(t, eventDispatcher.events, eventsCount)
+	})
+
+	t.Run("Open order cannot be paid", func(t *testing.T) {
+		orderID, err := orderService.CreateOrder(uuid.Must(uuid.NewV7()))
+		require.NoError(t, err)
+
+		err = orderService.SetStatus(orderID, model.Paid)
+		require.ErrorIs(t, err, service.ErrInvalidStatusTransition)
+	})
+}
+
+func TestOrderTotals(t *testing.T) {
+	repo := &mockOrderRepository{
+		store: map[uuid.UUID]*model.Order{},
+	}
+	eventDispatcher := &mockEventDispatcher{}
+
+	orderService := service.NewOrderService(repo, eventDispatcher, service.Options{
+		PricingRules: model.PricingRules{
+			DiscountRate: 1000,
+			TaxRate:      2000,
+		},
+	})
+
+	orderID, err := orderService.CreateOrder(uuid.Must(uuid.NewV7()))
+	require.NoError(t, err)
+	require.Empty(t, repo.store[orderID].Totals)
+
+	_, err = orderService.AddItem(orderID, uuid.Must(uuid.NewV7()), mustMoney(t, 1000, "USD"))
+	require.NoError(t, err)
+	_, err = orderService.AddItem(orderID, uuid.Must(uuid.NewV7()), mustMoney(t, 555, "USD"))
+	require.NoError(t, err)
+	_, err = orderService.AddItem(orderID, uuid.Must(uuid.NewV7()), mustMoney(t, 300, "EUR"))
+	require.NoError(t, err)
+
+	require.Equal(t, []model.OrderTotal{
+		{
+			Subtotal: mustMoney(t, 300, "EUR"),
+			Discount: mustMoney(t, 30, "EUR"),
+			Tax:      mustMoney(t, 54, "EUR"),
+			Total:    mustMoney(t, 324, "EUR"),
+		},
+		{
+			Subtotal: mustMoney(t, 1555, "USD"),
+			Discount: mustMoney(t, 156, "USD"),
+			Tax:      mustMoney(t, 280, "USD"),
+			Total:    mustMoney(t, 1679, "USD"),
+		},
+	}, repo.store[orderID].Totals)
+}
+
+func TestOrderItemQuantity(t *testing.T) {
+	repo := &mockOrderRepository{
+		store: map[uuid.UUID]*model.Order{},
+	}
+	eventDispatcher := &mockEventDispatcher{}
+
+	orderService := service.NewOrderService(repo, eventDispatcher, service.Options{MergeDuplicateItems: true})
+
+	orderID, err := orderService.CreateOrder(uuid.Must(uuid.NewV7()))
+	require.NoError(t, err)
+	productID := uuid.Must(uuid.NewV7())
+
+	t.Run("Same product is merged", func(t *testing.T) {
+		itemID, err := orderService.AddItem(orderID, productID, mustMoney(t, 100, "USD"))
+		require.NoError(t, err)
+		mergedItemID, err := orderService.AddItem(orderID, productID, mustMoney(t, 100, "USD"))
+		require.NoError(t, err)
+
+		require.Equal(t, itemID, mergedItemID)
+		require.Len(t, repo.store[orderID].Items, 1)
+		require.Equal(t, 2, repo.store[orderID].Items[0].Quantity)
+		require.Equal(t, mustMoney(t, 200, "USD"), repo.store[orderID].Totals[0].Total)
+		require.Equal(t, model.OrderItemChanged{
+			OrderID: orderID,
+			QuantityChanges: []model.ItemQuantityChange{
+				{ItemID: itemID, ProductID: productID, Delta: 1},
+			},
+		}, eventDispatcher.events[len(eventDispatcher.events)-1])
+	})
+
+	t.Run("Change quantity", func(t *testing.T) {
+		itemID := repo.store[orderID].Items[0].ID
+
+		require.NoError(t, orderService.ChangeQuantity(orderID, itemID, 5))
+		require.Equal(t, 5, repo.store[orderID].Items[0].Quantity)
+		require.Equal(t, mustMoney(t, 500, "USD"), repo.store[orderID].Totals[0].Total)
+		require.Equal(t, model.OrderItemChanged{
+			OrderID: orderID,
+			QuantityChanges: []model.ItemQuantityChange{
+				{ItemID: itemID, ProductID: productID, Delta: 3},
+			},
+		}, eventDispatcher.events[len(eventDispatcher.events)-1])
+
+		require.ErrorIs(t, orderService.ChangeQuantity(orderID, itemID, 0), service.ErrInvalidQuantity)
+		require.ErrorIs(t, orderService.ChangeQuantity(orderID, uuid.Must(uuid.NewV7()), 1), model.ErrItemNotFound)
+	})
+}
+
+func TestDeleteItem(t *testing.T) {
+	repo := &mockOrderRepository{
+		store: map[uuid.UUID]*model.Order{},
+	}
+	eventDispatcher := &mockEventDispatcher{}
+
+	orderService := service.NewOrderService(repo, eventDispatcher, service.Options{})
+
+	orderID, err := orderService.CreateOrder(uuid.Must(uuid.NewV7()))
+	require.NoError(t, err)
+	productID := uuid.Must(uuid.NewV7())
+	itemID, err := orderService.AddItem(orderID, productID, mustMoney(t, 100, "USD"))
+	require.NoError(t, err)
+	require.NoError(t, orderService.ChangeQuantity(orderID, itemID, 3))
+	keptItemID, err := orderService.AddItem(orderID, uuid.Must(uuid.NewV7()), mustMoney(t, 50, "USD"))
+	require.NoError(t, err)
+
+	t.Run("Delete item", func(t *testing.T) {
+		require.NoError(t, orderService.DeleteItem(orderID, itemID))
+
+		order := repo.store[orderID]
+		require.Len(t, order.Items, 1)
+		require.Equal(t, keptItemID, order.Items[0].ID)
+		require.Equal(t, mustMoney(t, 50, "USD"), order.Totals[0].Total)
+		require.Equal(t, model.OrderItemChanged{
+			OrderID:      orderID,
+			RemovedItems: []uuid.UUID{itemID},
+			QuantityChanges: []model.ItemQuantityChange{
+				{ItemID: itemID, ProductID: productID, Delta: -3},
+			},
+		}, eventDispatcher.events[len(eventDispatcher.events)-1])
+	})
+
+	t.Run("Unknown item", func(t *testing.T) {
+		eventsCount := len(eventDispatcher.events)
+		require.ErrorIs(t, orderService.DeleteItem(orderID, itemID), model.ErrItemNotFound)
+		require.Len(t, repo.store[orderID].Items, 1)
+		require.Len(t, eventDispatcher.events, eventsCount)
+	})
+
+	t.Run("Last item leaves no totals", func(t *testing.T) {
+		require.NoError(t, orderService.DeleteItem(orderID, keptItemID))
+		require.Empty(t, repo.store[orderID].Items)
+		require.Empty(t, repo.store[orderID].Totals)
+	})
+
+	t.Run("Only open order items can be deleted", func(t *testing.T) {
+		itemID, err := orderService.AddItem(orderID, productID, mustMoney(t, 100, "USD"))
+		require.NoError(t, err)
+		require.NoError(t, orderService.SetStatus(orderID, model.Pending))
+
+		require.ErrorIs(t, orderService.DeleteItem(orderID, itemID), service.ErrInvalidOrderStatus)
+		require.Len(t, repo.store[orderID].Items, 1)
+	})
+}
+
+func TestDeleteOrder(t *testing.T) {
+	repo := &mockOrderRepository{
+		store: map[uuid.UUID]*model.Order{},
+	}
+	eventDispatcher := &mockEventDispatcher{}
+
+	orderService := service.NewOrderService(repo, eventDispatcher, service.Options{})
+
+	t.Run("Delete open order", func(t *testing.T) {
+		orderID, err := orderService.CreateOrder(uuid.Must(uuid.NewV7()))
+		require.NoError(t, err)
+
+		require.NoError(t, orderService.DeleteOrder(orderID))
+		require.NotNil(t, repo.store[orderID].DeletedAt)
+		require.Equal(t, model.OrderDeleted{}.Type(), eventDispatcher.events[len(eventDispatcher.events)-1].Type())
+
+		require.ErrorIs(t, orderService.DeleteOrder(orderID), model.ErrOrderNotFound)
+		require.ErrorIs(t, orderService.SetStatus(orderID, model.Pending), model.ErrOrderNotFound)
+	})
+
+	t.Run("Paid order cannot be deleted", func(t *testing.T) {
+		orderID, err := orderService.CreateOrder(uuid.Must(uuid.NewV7()))
+		require.NoError(t, err)
+		require.NoError(t, orderService.SetStatus(orderID, model.Pending))
+		require.NoError(t, orderService.SetStatus(orderID, model.Paid))
+
+		err = orderService.DeleteOrder(orderID)
+		require.ErrorIs(t, err, service.ErrPaidOrderDeletion)
+		require.Equal(t, model.FailedPreconditionError, model.CategoryOf(err))
+		require.Nil(t, repo.store[orderID].DeletedAt)
+	})
+}
+
 var _ model.OrderRepository = &mockOrderRepository{}
 
 type mockOrderRepository struct {
@@ -41,19 +268,47 @@ func (m mockOrderRepository) NextID() (uuid.UUID, error) {
 }
 
 func (m mockOrderRepository) Store(order *model.Order) error {
+	order.Version++
 	m.store[order.ID] = order
 	return nil
 }
 
-func (m mockOrderRepository) Find(id uuid.UUID) (*model.Order, error) {
-	if order, ok := m.store[id]; ok && order.DeletedAt != nil {
-		return order, nil
+func (m mockOrderRepository) Find(spec model.FindSpec) (*model.Order, error) {
+	for _, order := range m.store {
+		if matchesSpec(order, spec) {
+			return order, nil
+		}
 	}
 	return nil, model.ErrOrderNotFound
 }
 
+func (m mockOrderRepository) List(spec model.FindSpec, page model.Page) ([]model.Order, error) {
+	var orders []model.Order
+	for _, order := range m.store {
+		if matchesSpec(order, spec) && order.ID.String() > page.After.String() {
+			orders = append(orders, *order)
+		}
+	}
+	slices.SortFunc(orders, func(a, b model.Order) int {
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	if len(orders) > page.Limit {
+		orders = orders[:page.Limit]
+	}
+	return orders, nil
+}
+
+func matchesSpec(order *model.Order, spec model.FindSpec) bool {
+	return (spec.OrderID == nil || order.ID == *spec.OrderID) &&
+		(spec.CustomerID == nil || order.CustomerID == *spec.CustomerID) &&
+		(len(spec.Statuses) == 0 || slices.Contains(spec.Statuses, order.Status)) &&
+		(spec.CreatedFrom == nil || !order.CreatedAt.Before(*spec.CreatedFrom)) &&
+		(spec.CreatedTo == nil || order.CreatedAt.Before(*spec.CreatedTo)) &&
+		(spec.IncludeDeleted || order.DeletedAt == nil)
+}
+
 func (m mockOrderRepository) Delete(id uuid.UUID) error {
-	if order, ok := m.store[id]; ok && order.DeletedAt != nil {
+	if order, ok := m.store[id]; ok && order.DeletedAt == nil {
 		order.DeletedAt = toPtr(time.Now())
 		return nil
 	}
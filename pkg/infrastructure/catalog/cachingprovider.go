@@ -0,0 +1,153 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+)
+
+const (
+	cacheHit         = "hit"
+	cacheNegativeHit = "negative_hit"
+	cacheMiss        = "miss"
+)
+
+type CacheOptions struct {
+	TTL time.Duration
+	// NegativeTTL is how long unknown products are remembered
+	NegativeTTL time.Duration
+	MaxEntries  int
+}
+
+// NewCachingProductProvider caches prices returned by provider.
+// Concurrent lookups of the same product are collapsed into a single call
+func NewCachingProductProvider(
+	provider service.ProductProvider,
+	options CacheOptions,
+	registerer prometheus.Registerer,
+) service.ProductProvider {
+	lookups := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "order_product_price_cache_lookups_total",
+		Help: "Product price cache lookups by result, hit ratio is hit / sum",
+	}, []string{"result"})
+	registerer.MustRegister(lookups)
+
+	return &cachingProductProvider{
+		provider: provider,
+		options:  options,
+		entries:  make(map[uuid.UUID]cacheEntry),
+		lookups:  lookups,
+	}
+}
+
+type cachingProductProvider struct {
+	provider service.ProductProvider
+	options  CacheOptions
+
+	mu      sync.RWMutex
+	entries map[uuid.UUID]cacheEntry
+	group   singleflight.Group
+
+	lookups *prometheus.CounterVec
+}
+
+type cacheEntry struct {
+	price     model.Money
+	err       error
+	expiresAt time.Time
+}
+
+func (p *cachingProductProvider) ActualPrice(ctx context.Context, productID uuid.UUID) (model.Money, error) {
+	if entry, ok := p.cached(productID); ok {
+		if entry.err != nil {
+			p.lookups.WithLabelValues(cacheNegativeHit).Inc()
+		} else {
+			p.lookups.WithLabelValues(cacheHit).Inc()
+		}
+		return entry.price, entry.err
+	}
+	p.lookups.WithLabelValues(cacheMiss).Inc()
+
+	// shared call must not be cancelled when the caller that started it goes away
+	resultCh := p.group.DoChan(productID.String(), func() (interface{}, error) {
+		price, err := p.provider.ActualPrice(context.WithoutCancel(ctx), productID)
+		p.store(productID, price, err)
+		return price, err
+	})
+
+	select {
+	case <-ctx.Done():
+		return model.Money{}, ctx.Err()
+	case result := <-resultCh:
+		if result.Err != nil {
+			return model.Money{}, result.Err
+		}
+		return result.Val.(model.Money), nil
+	}
+}
+
+func (p *cachingProductProvider) cached(productID uuid.UUID) (cacheEntry, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	entry, ok := p.entries[productID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (p *cachingProductProvider) store(productID uuid.UUID, price model.Money, err error) {
+	ttl := p.options.TTL
+	if err != nil {
+		if !errors.Is(err, model.ErrProductNotFound) {
+			return
+		}
+		ttl = p.options.NegativeTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.entries[productID]; !ok && p.options.MaxEntries > 0 && len(p.entries) >= p.options.MaxEntries {
+		p.evict()
+	}
+	p.entries[productID] = cacheEntry{
+		price:     price,
+		err:       err,
+		expiresAt: time.Now().Add(ttl),
+	}
+}
+
+// evict removes expired entries, if there are none it drops the one which expires first to make room
+func (p *cachingProductProvider) evict() {
+	now := time.Now()
+	var (
+		oldestID  uuid.UUID
+		oldestSet bool
+		oldest    time.Time
+	)
+	for productID, entry := range p.entries {
+		if now.After(entry.expiresAt) {
+			delete(p.entries, productID)
+			continue
+		}
+		if !oldestSet || entry.expiresAt.Before(oldest) {
+			oldestID, oldest, oldestSet = productID, entry.expiresAt, true
+		}
+	}
+	if len(p.entries) >= p.options.MaxEntries && oldestSet {
+		delete(p.entries, oldestID)
+	}
+}
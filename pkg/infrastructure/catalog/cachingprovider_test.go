@@ -0,0 +1,169 @@
+package catalog_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/domain/model"
+	"order/pkg/infrastructure/catalog"
+)
+
+func TestCachingProductProvider(t *testing.T) {
+	price, err := model.NewMoney(500, "USD")
+	require.NoError(t, err)
+	knownProductID := uuid.Must(uuid.NewV7())
+
+	newProvider := func(t *testing.T) (*countingProductProvider, *prometheus.Registry, func(uuid.UUID) (model.Money, error)) {
+		t.Helper()
+		provider := &countingProductProvider{
+			prices:  map[uuid.UUID]model.Money{knownProductID: price},
+			release: make(chan struct{}),
+		}
+		close(provider.release)
+		registry := prometheus.NewRegistry()
+		cachingProvider := catalog.NewCachingProductProvider(provider, catalog.CacheOptions{
+			TTL:         time.Minute,
+			NegativeTTL: time.Minute,
+			MaxEntries:  10,
+		}, registry)
+		return provider, registry, func(productID uuid.UUID) (model.Money, error) {
+			return cachingProvider.ActualPrice(context.Background(), productID)
+		}
+	}
+
+	t.Run("Price is cached", func(t *testing.T) {
+		provider, registry, actualPrice := newProvider(t)
+
+		for i := 0; i < 3; i++ {
+			p, err := actualPrice(knownProductID)
+			require.NoError(t, err)
+			require.Equal(t, price, p)
+		}
+		require.EqualValues(t, 1, provider.calls.Load())
+		require.Equal(t, 2, countLookups(t, registry, "hit"))
+		require.Equal(t, 1, countLookups(t, registry, "miss"))
+	})
+
+	t.Run("Unknown product is cached", func(t *testing.T) {
+		provider, registry, actualPrice := newProvider(t)
+		unknownProductID := uuid.Must(uuid.NewV7())
+
+		for i := 0; i < 2; i++ {
+			_, err := actualPrice(unknownProductID)
+			require.ErrorIs(t, err, model.ErrProductNotFound)
+		}
+		require.EqualValues(t, 1, provider.calls.Load())
+		require.Equal(t, 1, countLookups(t, registry, "negative_hit"))
+	})
+
+	t.Run("Metrics are registered in the given registry only", func(t *testing.T) {
+		_, firstRegistry, firstPrice := newProvider(t)
+		_, secondRegistry, _ := newProvider(t)
+
+		_, err := firstPrice(knownProductID)
+		require.NoError(t, err)
+		require.Equal(t, 1, countLookups(t, firstRegistry, "miss"))
+		require.Equal(t, 0, countLookups(t, secondRegistry, "miss"))
+
+		families, err := prometheus.DefaultGatherer.Gather()
+		require.NoError(t, err)
+		for _, family := range families {
+			require.NotEqual(t, "order_product_price_cache_lookups_total", family.GetName())
+		}
+	})
+
+	t.Run("Entry which expires first is evicted when cache is full", func(t *testing.T) {
+		productIDs := []uuid.UUID{knownProductID, uuid.Must(uuid.NewV7()), uuid.Must(uuid.NewV7())}
+		provider := &countingProductProvider{
+			prices:  map[uuid.UUID]model.Money{},
+			release: make(chan struct{}),
+		}
+		for _, productID := range productIDs {
+			provider.prices[productID] = price
+		}
+		close(provider.release)
+		cachingProvider := catalog.NewCachingProductProvider(provider, catalog.CacheOptions{
+			TTL:        time.Minute,
+			MaxEntries: 2,
+		}, prometheus.NewRegistry())
+
+		for _, productID := range productIDs {
+			_, err := cachingProvider.ActualPrice(context.Background(), productID)
+			require.NoError(t, err)
+		}
+		require.EqualValues(t, 3, provider.calls.Load())
+
+		for _, productID := range productIDs[1:] {
+			_, err := cachingProvider.ActualPrice(context.Background(), productID)
+			require.NoError(t, err)
+		}
+		require.EqualValues(t, 3, provider.calls.Load())
+
+		_, err := cachingProvider.ActualPrice(context.Background(), productIDs[0])
+		require.NoError(t, err)
+		require.EqualValues(t, 4, provider.calls.Load())
+	})
+
+	t.Run("Concurrent lookups are collapsed", func(t *testing.T) {
+		provider := &countingProductProvider{
+			prices:  map[uuid.UUID]model.Money{knownProductID: price},
+			release: make(chan struct{}),
+		}
+		cachingProvider := catalog.NewCachingProductProvider(provider, catalog.CacheOptions{TTL: time.Minute}, prometheus.NewRegistry())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p, err := cachingProvider.ActualPrice(context.Background(), knownProductID)
+				assert.NoError(t, err)
+				assert.Equal(t, price, p)
+			}()
+		}
+		require.Eventually(t, func() bool { return provider.calls.Load() == 1 }, time.Second, time.Millisecond)
+		close(provider.release)
+		wg.Wait()
+		require.EqualValues(t, 1, provider.calls.Load())
+	})
+}
+
+func countLookups(t *testing.T, registry *prometheus.Registry, result string) int {
+	t.Helper()
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "result" && label.GetValue() == result {
+					return int(metric.GetCounter().GetValue())
+				}
+			}
+		}
+	}
+	return 0
+}
+
+type countingProductProvider struct {
+	prices  map[uuid.UUID]model.Money
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (p *countingProductProvider) ActualPrice(_ context.Context, productID uuid.UUID) (model.Money, error) {
+	p.calls.Add(1)
+	<-p.release
+	price, ok := p.prices[productID]
+	if !ok {
+		return model.Money{}, model.ErrProductNotFound
+	}
+	return price, nil
+}
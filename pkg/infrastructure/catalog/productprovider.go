@@ -0,0 +1,53 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "order/api/client/productcatalog"
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+)
+
+func NewProductProvider(conn grpc.ClientConnInterface) service.ProductProvider {
+	return &productProvider{
+		client: api.NewProductCatalogServiceClient(conn),
+	}
+}
+
+type productProvider struct {
+	client api.ProductCatalogServiceClient
+}
+
+func (p productProvider) ActualPrice(ctx context.Context, productID uuid.UUID) (model.Money, error) {
+	resp, err := p.client.GetProductPrice(ctx, &api.GetProductPriceRequest{
+		ProductId: productID.String(),
+	})
+	if err != nil {
+		return model.Money{}, translateError(err)
+	}
+
+	return model.NewMoney(resp.GetPrice().GetAmount(), resp.GetPrice().GetCurrency())
+}
+
+// translateError maps catalog gRPC errors to domain errors keeping the original status in the message
+func translateError(err error) error {
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	switch s.Code() {
+	case codes.NotFound:
+		return fmt.Errorf("%w: %s", model.ErrProductNotFound, s.Message())
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return fmt.Errorf("%w: %s", model.ErrProductUnavailable, s.Message())
+	default:
+		return err
+	}
+}
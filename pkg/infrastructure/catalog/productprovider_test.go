@@ -0,0 +1,81 @@
+package catalog_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	api "order/api/client/productcatalog"
+	"order/pkg/domain/model"
+	"order/pkg/infrastructure/catalog"
+)
+
+func TestProductProvider(t *testing.T) {
+	stub := newStubServer()
+	conn := startStubServer(t, stub)
+	provider := catalog.NewProductProvider(conn)
+
+	productID := uuid.Must(uuid.NewV7())
+	price, err := model.NewMoney(1999, "USD")
+	require.NoError(t, err)
+	stub.SetPrice(productID, price)
+
+	t.Run("Actual price", func(t *testing.T) {
+		actualPrice, err := provider.ActualPrice(context.Background(), productID)
+		require.NoError(t, err)
+		require.Equal(t, price, actualPrice)
+	})
+
+	t.Run("Unknown product", func(t *testing.T) {
+		_, err := provider.ActualPrice(context.Background(), uuid.Must(uuid.NewV7()))
+		require.ErrorIs(t, err, model.ErrProductNotFound)
+	})
+
+	t.Run("Catalog unavailable", func(t *testing.T) {
+		_, err := catalog.NewProductProvider(startStoppedServer(t)).ActualPrice(context.Background(), productID)
+		require.ErrorIs(t, err, model.ErrProductUnavailable)
+	})
+}
+
+func startStubServer(t *testing.T, stub api.ProductCatalogServiceServer) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	api.RegisterProductCatalogServiceServer(server, stub)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return dial(t, listener)
+}
+
+func startStoppedServer(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	require.NoError(t, listener.Close())
+
+	return dial(t, listener)
+}
+
+func dial(t *testing.T, listener *bufconn.Listener) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
@@ -0,0 +1,54 @@
+package catalog_test
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "order/api/client/productcatalog"
+	"order/pkg/domain/model"
+)
+
+// stubServer is an in-memory product catalog
+type stubServer struct {
+	mu     sync.RWMutex
+	prices map[uuid.UUID]model.Money
+}
+
+var _ api.ProductCatalogServiceServer = &stubServer{}
+
+func newStubServer() *stubServer {
+	return &stubServer{
+		prices: make(map[uuid.UUID]model.Money),
+	}
+}
+
+func (s *stubServer) SetPrice(productID uuid.UUID, price model.Money) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prices[productID] = price
+}
+
+func (s *stubServer) GetProductPrice(_ context.Context, req *api.GetProductPriceRequest) (*api.GetProductPriceResponse, error) {
+	productID, err := uuid.Parse(req.ProductId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid product_id: %v", err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	price, ok := s.prices[productID]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "product %s not found", productID)
+	}
+
+	return &api.GetProductPriceResponse{
+		Price: &api.Money{
+			Amount:   price.Amount(),
+			Currency: price.Currency(),
+		},
+	}, nil
+}
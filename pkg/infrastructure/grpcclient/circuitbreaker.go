@@ -0,0 +1,125 @@
+package grpcclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type callOutcome int
+
+const (
+	// callAbandoned is neither success nor failure: the caller gave up or the call panicked
+	callAbandoned callOutcome = iota
+	callSucceeded
+	callFailed
+)
+
+// circuitBreaker opens after failureThreshold consecutive unavailability failures and rejects calls for openTimeout,
+// then lets a single probe call through to decide whether to close again
+func newCircuitBreaker(failureThreshold int, openTimeout time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+	}
+}
+
+type circuitBreaker struct {
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+func (b *circuitBreaker) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if b.failureThreshold <= 0 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if !b.allow() {
+			return status.Errorf(codes.Unavailable, "circuit breaker is open, %s is not called", method)
+		}
+
+		// outcome is recorded in defer, so a panicking call does not leave the breaker half-open forever
+		outcome := callAbandoned
+		defer func() {
+			b.record(outcome)
+		}()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		outcome = callOutcomeOf(ctx, err)
+		return err
+	}
+}
+
+func callOutcomeOf(ctx context.Context, err error) callOutcome {
+	switch {
+	case err == nil:
+		return callSucceeded
+	case ctx.Err() != nil:
+		// caller cancelled the call or ran out of its own deadline, it says nothing about the target
+		return callAbandoned
+	case !isUnavailableCode(status.Code(err)):
+		return callSucceeded
+	default:
+		return callFailed
+	}
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// probe call is in flight
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) record(outcome callOutcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch outcome {
+	case callAbandoned:
+		if b.state == breakerHalfOpen {
+			// openedAt is kept, so the next call probes again right away
+			b.state = breakerOpen
+		}
+	case callSucceeded:
+		b.state = breakerClosed
+		b.failures = 0
+	case callFailed:
+		b.failures++
+		if b.state == breakerHalfOpen || b.failures >= b.failureThreshold {
+			b.state = breakerOpen
+			b.openedAt = b.now()
+		}
+	}
+}
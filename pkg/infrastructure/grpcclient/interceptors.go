@@ -0,0 +1,45 @@
+package grpcclient
+
+import (
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type Config struct {
+	// Timeout limits every single attempt, zero disables it
+	Timeout time.Duration
+
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// IdempotentMethods are full gRPC method names which are safe to retry
+	IdempotentMethods []string
+
+	BreakerFailureThreshold int
+	BreakerOpenTimeout      time.Duration
+}
+
+// DialOptions builds interceptors for a single target: circuit breaker wraps retries, retries wrap per-attempt timeout.
+// Request id of the current call is forwarded with every attempt
+func DialOptions(config Config) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(
+			requestIDInterceptor(),
+			newCircuitBreaker(config.BreakerFailureThreshold, config.BreakerOpenTimeout).UnaryClientInterceptor(),
+			newRetrier(config.MaxRetries, config.RetryBaseDelay, config.RetryMaxDelay, config.IdempotentMethods).UnaryClientInterceptor(),
+			timeoutInterceptor(config.Timeout),
+		),
+	}
+}
+
+// isUnavailableCode reports codes which mean that the target is unhealthy rather than the request is wrong
+func isUnavailableCode(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
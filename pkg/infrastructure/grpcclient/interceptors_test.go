@@ -0,0 +1,202 @@
+package grpcclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"order/pkg/infrastructure/requestid"
+)
+
+const testMethod = "/Test.Service/Get"
+
+func TestRetrier(t *testing.T) {
+	t.Run("Idempotent call is retried", func(t *testing.T) {
+		invoker := &fakeInvoker{errs: []error{
+			status.Error(codes.Unavailable, "unavailable"),
+			status.Error(codes.Unavailable, "unavailable"),
+		}}
+		interceptor := newRetrier(3, time.Millisecond, time.Millisecond, []string{testMethod}).UnaryClientInterceptor()
+
+		require.NoError(t, interceptor(context.Background(), testMethod, nil, nil, nil, invoker.invoke))
+		require.Equal(t, 3, invoker.calls)
+	})
+
+	t.Run("Retries are bounded", func(t *testing.T) {
+		invoker := &fakeInvoker{errs: []error{
+			status.Error(codes.Unavailable, "unavailable"),
+			status.Error(codes.Unavailable, "unavailable"),
+			status.Error(codes.Unavailable, "unavailable"),
+		}}
+		interceptor := newRetrier(1, time.Millisecond, time.Millisecond, []string{testMethod}).UnaryClientInterceptor()
+
+		require.Equal(t, codes.Unavailable, status.Code(interceptor(context.Background(), testMethod, nil, nil, nil, invoker.invoke)))
+		require.Equal(t, 2, invoker.calls)
+	})
+
+	t.Run("Non idempotent call and client errors are not retried", func(t *testing.T) {
+		invoker := &fakeInvoker{errs: []error{status.Error(codes.Unavailable, "unavailable")}}
+		interceptor := newRetrier(3, time.Millisecond, time.Millisecond, nil).UnaryClientInterceptor()
+		require.Error(t, interceptor(context.Background(), testMethod, nil, nil, nil, invoker.invoke))
+		require.Equal(t, 1, invoker.calls)
+
+		invoker = &fakeInvoker{errs: []error{status.Error(codes.NotFound, "not found")}}
+		interceptor = newRetrier(3, time.Millisecond, time.Millisecond, []string{testMethod}).UnaryClientInterceptor()
+		require.Error(t, interceptor(context.Background(), testMethod, nil, nil, nil, invoker.invoke))
+		require.Equal(t, 1, invoker.calls)
+	})
+}
+
+func TestRequestIDInterceptor(t *testing.T) {
+	var outgoing metadata.MD
+	invoker := func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	interceptor := requestIDInterceptor()
+
+	ctx := requestid.WithRequestID(context.Background(), "req-42")
+	require.NoError(t, interceptor(ctx, testMethod, nil, nil, nil, invoker))
+	require.Equal(t, []string{"req-42"}, outgoing.Get(requestid.Header))
+	require.Equal(t, []string{"req-42"}, outgoing.Get(requestid.CorrelationHeader))
+
+	require.NoError(t, interceptor(context.Background(), testMethod, nil, nil, nil, invoker))
+	require.Empty(t, outgoing.Get(requestid.Header))
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+	interceptor := breaker.UnaryClientInterceptor()
+
+	failing := &fakeInvoker{errs: []error{
+		status.Error(codes.Unavailable, "unavailable"),
+		status.Error(codes.Unavailable, "unavailable"),
+		status.Error(codes.Unavailable, "unavailable"),
+	}}
+	for i := 0; i < 2; i++ {
+		require.Error(t, interceptor(context.Background(), testMethod, nil, nil, nil, failing.invoke))
+	}
+
+	t.Run("Open breaker rejects calls", func(t *testing.T) {
+		err := interceptor(context.Background(), testMethod, nil, nil, nil, failing.invoke)
+		require.Equal(t, codes.Unavailable, status.Code(err))
+		require.Equal(t, 2, failing.calls)
+	})
+
+	t.Run("Failed probe opens breaker again", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		require.Error(t, interceptor(context.Background(), testMethod, nil, nil, nil, failing.invoke))
+		require.Equal(t, 3, failing.calls)
+
+		require.Error(t, interceptor(context.Background(), testMethod, nil, nil, nil, failing.invoke))
+		require.Equal(t, 3, failing.calls)
+	})
+
+	t.Run("Successful probe closes breaker", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		healthy := &fakeInvoker{}
+		require.NoError(t, interceptor(context.Background(), testMethod, nil, nil, nil, healthy.invoke))
+		require.NoError(t, interceptor(context.Background(), testMethod, nil, nil, nil, healthy.invoke))
+		require.Equal(t, 2, healthy.calls)
+	})
+}
+
+func TestCircuitBreakerAbandonedProbe(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	openBreaker := func(t *testing.T) *circuitBreaker {
+		t.Helper()
+		now := time.Now()
+		breaker := newCircuitBreaker(1, time.Minute)
+		breaker.now = func() time.Time { return now }
+		failing := &fakeInvoker{errs: []error{unavailable}}
+		require.Error(t, breaker.UnaryClientInterceptor()(context.Background(), testMethod, nil, nil, nil, failing.invoke))
+		require.Equal(t, breakerOpen, breaker.state)
+		now = now.Add(time.Minute)
+		return breaker
+	}
+
+	t.Run("Cancelled probe opens breaker again", func(t *testing.T) {
+		breaker := openBreaker(t)
+		interceptor := breaker.UnaryClientInterceptor()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancelling := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+			cancel()
+			return status.Error(codes.Canceled, "cancelled")
+		}
+
+		require.Error(t, interceptor(ctx, testMethod, nil, nil, nil, cancelling))
+		require.Equal(t, breakerOpen, breaker.state)
+
+		// cancellation is not a failure, so the next call probes without waiting for another timeout
+		healthy := &fakeInvoker{}
+		require.NoError(t, interceptor(context.Background(), testMethod, nil, nil, nil, healthy.invoke))
+		require.Equal(t, breakerClosed, breaker.state)
+	})
+
+	t.Run("Probe past caller deadline is not a success", func(t *testing.T) {
+		breaker := openBreaker(t)
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+		defer cancel()
+		timedOut := &fakeInvoker{errs: []error{status.Error(codes.DeadlineExceeded, "deadline exceeded")}}
+
+		require.Error(t, breaker.UnaryClientInterceptor()(ctx, testMethod, nil, nil, nil, timedOut.invoke))
+		require.Equal(t, breakerOpen, breaker.state)
+		require.Equal(t, 1, breaker.failures)
+	})
+
+	t.Run("Panicking probe releases the probe slot", func(t *testing.T) {
+		breaker := openBreaker(t)
+		interceptor := breaker.UnaryClientInterceptor()
+		panicking := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+			panic("probe panicked")
+		}
+
+		require.Panics(t, func() {
+			_ = interceptor(context.Background(), testMethod, nil, nil, nil, panicking)
+		})
+		require.Equal(t, breakerOpen, breaker.state)
+
+		healthy := &fakeInvoker{}
+		require.NoError(t, interceptor(context.Background(), testMethod, nil, nil, nil, healthy.invoke))
+		require.Equal(t, 1, healthy.calls)
+	})
+
+	t.Run("Cancelled call does not reset failures of closed breaker", func(t *testing.T) {
+		breaker := newCircuitBreaker(2, time.Minute)
+		interceptor := breaker.UnaryClientInterceptor()
+		failing := &fakeInvoker{errs: []error{unavailable, unavailable}}
+		require.Error(t, interceptor(context.Background(), testMethod, nil, nil, nil, failing.invoke))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		cancelled := &fakeInvoker{errs: []error{status.Error(codes.Canceled, "cancelled")}}
+		require.Error(t, interceptor(ctx, testMethod, nil, nil, nil, cancelled.invoke))
+		require.Equal(t, 1, breaker.failures)
+
+		require.Error(t, interceptor(context.Background(), testMethod, nil, nil, nil, failing.invoke))
+		require.Equal(t, breakerOpen, breaker.state)
+	})
+}
+
+type fakeInvoker struct {
+	errs  []error
+	calls int
+}
+
+func (f *fakeInvoker) invoke(_ context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+	f.calls++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
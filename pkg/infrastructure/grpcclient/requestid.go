@@ -0,0 +1,23 @@
+package grpcclient
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"order/pkg/infrastructure/requestid"
+)
+
+// requestIDInterceptor forwards request and correlation ids of the current call to the target
+func requestIDInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if requestID := requestid.FromContext(ctx); requestID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx,
+				requestid.Header, requestID,
+				requestid.CorrelationHeader, requestid.CorrelationIDFromContext(ctx),
+			)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
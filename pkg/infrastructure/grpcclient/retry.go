@@ -0,0 +1,63 @@
+package grpcclient
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+func newRetrier(maxRetries int, baseDelay, maxDelay time.Duration, idempotentMethods []string) *retrier {
+	methods := make(map[string]struct{}, len(idempotentMethods))
+	for _, method := range idempotentMethods {
+		methods[method] = struct{}{}
+	}
+	return &retrier{
+		maxRetries: maxRetries,
+		baseDelay:  baseDelay,
+		maxDelay:   maxDelay,
+		methods:    methods,
+	}
+}
+
+type retrier struct {
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	methods    map[string]struct{}
+}
+
+func (r *retrier) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := r.methods[method]; !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		for attempt := 0; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || attempt >= r.maxRetries || !isUnavailableCode(status.Code(err)) || ctx.Err() != nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(r.backoff(attempt)):
+			}
+		}
+	}
+}
+
+// backoff uses full jitter: random delay up to exponentially growing cap
+func (r *retrier) backoff(attempt int) time.Duration {
+	ceiling := r.baseDelay << attempt
+	if ceiling <= 0 || ceiling > r.maxDelay {
+		ceiling = r.maxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) //nolint:gosec // jitter does not need a cryptographic source
+}
@@ -0,0 +1,62 @@
+package integrationevent
+
+import (
+	"context"
+	"fmt"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/outbox"
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+
+	appservice "order/pkg/application/service"
+	"order/pkg/domain/service"
+	"order/pkg/infrastructure/requestid"
+)
+
+// NewEventDispatcher stores events in the outbox of transport within the unit of work of ctx.
+// Every event gets a unique message id, which is also stored as outbox correlation_id like golib does.
+// Request and correlation ids of ctx become causation and correlation ids of the event,
+// events dispatched outside of a request are correlated by their message id
+func NewEventDispatcher(
+	transport string,
+	serializer outbox.EventSerializer[service.Event],
+	uow mysql.UnitOfWork,
+) outbox.EventDispatcher[service.Event] {
+	return &eventDispatcher{
+		serializer: serializer,
+		uow:        uow,
+		query:      fmt.Sprintf("INSERT INTO outbox_%s_event (correlation_id, event_type, payload) VALUES (?, ?, ?)", transport),
+	}
+}
+
+type eventDispatcher struct {
+	serializer outbox.EventSerializer[service.Event]
+	uow        mysql.UnitOfWork
+	query      string
+}
+
+func (d *eventDispatcher) Dispatch(ctx context.Context, event service.Event) error {
+	payload, err := d.serializer.Serialize(event)
+	if err != nil {
+		return err
+	}
+
+	envelope := Envelope{
+		MessageID:     requestid.New(),
+		CorrelationID: requestid.CorrelationIDFromContext(ctx),
+		CausationID:   requestid.FromContext(ctx),
+		Payload:       []byte(payload),
+	}
+	if envelope.CorrelationID == "" {
+		envelope.CorrelationID = envelope.MessageID
+	}
+	data, err := envelope.Marshal()
+	if err != nil {
+		return err
+	}
+
+	ctx = appservice.TransactionContext(ctx)
+	return d.uow.ExecuteWithClientContext(ctx, func(client mysql.ClientContext) error {
+		_, err := client.ExecContext(ctx, d.query, envelope.MessageID, event.Type(), data)
+		return err
+	})
+}
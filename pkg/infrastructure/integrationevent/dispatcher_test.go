@@ -0,0 +1,76 @@
+package integrationevent
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/domain/model"
+	"order/pkg/infrastructure/requestid"
+)
+
+func TestEventDispatcher(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	dispatcher := NewEventDispatcher("order", NewEventSerializer(), clientUnitOfWork{client: sqlx.NewDb(db, "mysql")})
+
+	event := model.OrderCreated{OrderID: uuid.Must(uuid.NewV7()), CustomerID: uuid.Must(uuid.NewV7())}
+	insert := regexp.QuoteMeta("INSERT INTO outbox_order_event (correlation_id, event_type, payload) VALUES (?, ?, ?)")
+	stored := make([]*storedEnvelope, 3)
+	for i := range stored {
+		stored[i] = &storedEnvelope{}
+		mock.ExpectExec(insert).
+			WithArgs(sqlmock.AnyArg(), event.Type(), stored[i]).
+			WillReturnResult(sqlmock.NewResult(int64(i+1), 1))
+	}
+
+	ctx := requestid.WithRequestID(context.Background(), "req-42")
+	require.NoError(t, dispatcher.Dispatch(ctx, event))
+	require.NoError(t, dispatcher.Dispatch(ctx, event))
+	require.NoError(t, dispatcher.Dispatch(context.Background(), event))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	first, second, background := stored[0].envelope, stored[1].envelope, stored[2].envelope
+	require.NotEqual(t, first.MessageID, second.MessageID)
+	require.Equal(t, "req-42", first.CorrelationID)
+	require.Equal(t, "req-42", first.CausationID)
+	require.Equal(t, "req-42", second.CorrelationID)
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(first.Payload, &payload))
+	require.Equal(t, event.OrderID.String(), payload["order_id"])
+
+	require.Equal(t, background.MessageID, background.CorrelationID)
+	require.Empty(t, background.CausationID)
+}
+
+// storedEnvelope captures the envelope passed as payload argument
+type storedEnvelope struct {
+	envelope Envelope
+}
+
+func (s *storedEnvelope) Match(value driver.Value) bool {
+	data, ok := value.(string)
+	if !ok {
+		return false
+	}
+	s.envelope, ok = UnmarshalEnvelope(data)
+	return ok
+}
+
+type clientUnitOfWork struct {
+	client mysql.ClientContext
+}
+
+func (u clientUnitOfWork) ExecuteWithClientContext(_ context.Context, callback func(client mysql.ClientContext) error) error {
+	return callback(u.client)
+}
@@ -0,0 +1,32 @@
+package integrationevent
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// Envelope is stored as outbox payload, so event metadata passes through the outbox tables owned by golib
+type Envelope struct {
+	// MessageID is unique per event, consumers deduplicate redelivered messages by it
+	MessageID string `json:"message_id"`
+	// CorrelationID ties together events of one flow
+	CorrelationID string `json:"correlation_id"`
+	// CausationID is the request which caused the event
+	CausationID string          `json:"causation_id"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+func (e Envelope) Marshal() (string, error) {
+	data, err := json.Marshal(e)
+	return string(data), errors.WithStack(err)
+}
+
+// UnmarshalEnvelope returns false for payloads stored before events were wrapped into envelope
+func UnmarshalEnvelope(data string) (Envelope, bool) {
+	var envelope Envelope
+	if err := json.Unmarshal([]byte(data), &envelope); err != nil || envelope.MessageID == "" || envelope.Payload == nil {
+		return Envelope{}, false
+	}
+	return envelope, true
+}
@@ -0,0 +1,180 @@
+package integrationevent
+
+import (
+	"encoding/json"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/application/outbox"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"order/pkg/domain/model"
+	"order/pkg/domain/service"
+)
+
+var ErrUnknownEvent = errors.New("unknown event")
+
+func NewEventSerializer() outbox.EventSerializer[service.Event] {
+	return &eventSerializer{}
+}
+
+type eventSerializer struct{}
+
+func (s eventSerializer) Serialize(event service.Event) (string, error) {
+	var payload interface{}
+	switch e := event.(type) {
+	case model.OrderCreated:
+		payload = orderCreated{
+			OrderID:    e.OrderID,
+			CustomerID: e.CustomerID,
+		}
+	case model.OrderItemChanged:
+		quantityChanges := make([]itemQuantityChange, 0, len(e.QuantityChanges))
+		for _, change := range e.QuantityChanges {
+			quantityChanges = append(quantityChanges, itemQuantityChange(change))
+		}
+		payload = orderItemChanged{
+			OrderID:         e.OrderID,
+			AddedItems:      e.AddedItems,
+			RemovedItems:    e.RemovedItems,
+			QuantityChanges: quantityChanges,
+		}
+	case model.OrderStatusChanged:
+		payload = orderStatusChanged{
+			OrderID:   e.OrderID,
+			OldStatus: e.OldStatus.String(),
+			NewStatus: e.NewStatus.String(),
+		}
+	case model.OrderRepriced:
+		priceChanges := make([]itemPriceChange, 0, len(e.PriceChanges))
+		for _, change := range e.PriceChanges {
+			priceChanges = append(priceChanges, itemPriceChange{
+				ItemID:    change.ItemID,
+				ProductID: change.ProductID,
+				OldPrice:  toMoney(change.OldPrice),
+				NewPrice:  toMoney(change.NewPrice),
+			})
+		}
+		payload = orderRepriced{
+			OrderID:      e.OrderID,
+			PriceChanges: priceChanges,
+		}
+	case model.OrderCancelled:
+		items := make([]item, 0, len(e.Items))
+		for _, i := range e.Items {
+			items = append(items, item{
+				ItemID:    i.ID,
+				ProductID: i.ProductID,
+				Price:     toMoney(i.Price),
+				Quantity:  i.Quantity,
+			})
+		}
+		totals := make([]orderTotal, 0, len(e.Totals))
+		for _, total := range e.Totals {
+			totals = append(totals, orderTotal{
+				Subtotal: toMoney(total.Subtotal),
+				Discount: toMoney(total.Discount),
+				Tax:      toMoney(total.Tax),
+				Total:    toMoney(total.Total),
+			})
+		}
+		payload = orderCancelled{
+			OrderID:    e.OrderID,
+			CustomerID: e.CustomerID,
+			OldStatus:  e.OldStatus.String(),
+			Reason:     e.Reason.String(),
+			Actor:      e.Actor,
+			Items:      items,
+			Totals:     totals,
+		}
+	case model.OrderDeleted:
+		payload = orderDeleted{
+			OrderID:    e.OrderID,
+			CustomerID: e.CustomerID,
+		}
+	default:
+		return "", errors.Wrapf(ErrUnknownEvent, "type %q", event.Type())
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(data), nil
+}
+
+type orderCreated struct {
+	OrderID    uuid.UUID `json:"order_id"`
+	CustomerID uuid.UUID `json:"customer_id"`
+}
+
+type orderItemChanged struct {
+	OrderID         uuid.UUID            `json:"order_id"`
+	AddedItems      []uuid.UUID          `json:"added_items,omitempty"`
+	RemovedItems    []uuid.UUID          `json:"removed_items,omitempty"`
+	QuantityChanges []itemQuantityChange `json:"quantity_changes,omitempty"`
+}
+
+type itemQuantityChange struct {
+	ItemID    uuid.UUID `json:"item_id"`
+	ProductID uuid.UUID `json:"product_id"`
+	Delta     int       `json:"delta"`
+}
+
+type orderStatusChanged struct {
+	OrderID   uuid.UUID `json:"order_id"`
+	OldStatus string    `json:"old_status"`
+	NewStatus string    `json:"new_status"`
+}
+
+type orderDeleted struct {
+	OrderID    uuid.UUID `json:"order_id"`
+	CustomerID uuid.UUID `json:"customer_id"`
+}
+
+type orderRepriced struct {
+	OrderID      uuid.UUID         `json:"order_id"`
+	PriceChanges []itemPriceChange `json:"price_changes"`
+}
+
+type itemPriceChange struct {
+	ItemID    uuid.UUID `json:"item_id"`
+	ProductID uuid.UUID `json:"product_id"`
+	OldPrice  money     `json:"old_price"`
+	NewPrice  money     `json:"new_price"`
+}
+
+type orderCancelled struct {
+	OrderID    uuid.UUID    `json:"order_id"`
+	CustomerID uuid.UUID    `json:"customer_id"`
+	OldStatus  string       `json:"old_status"`
+	Reason     string       `json:"reason"`
+	Actor      string       `json:"actor"`
+	Items      []item       `json:"items"`
+	Totals     []orderTotal `json:"totals"`
+}
+
+type item struct {
+	ItemID    uuid.UUID `json:"item_id"`
+	ProductID uuid.UUID `json:"product_id"`
+	Price     money     `json:"price"`
+	Quantity  int       `json:"quantity"`
+}
+
+type orderTotal struct {
+	Subtotal money `json:"subtotal"`
+	Discount money `json:"discount"`
+	Tax      money `json:"tax"`
+	Total    money `json:"total"`
+}
+
+type money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func toMoney(m model.Money) money {
+	return money{
+		Amount:   m.Amount(),
+		Currency: m.Currency(),
+	}
+}
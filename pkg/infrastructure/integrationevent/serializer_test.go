@@ -0,0 +1,104 @@
+package integrationevent
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/domain/model"
+	"order/pkg/domain/service"
+)
+
+func TestEventSerializer(t *testing.T) {
+	orderID := uuid.MustParse("0190a1b2-0000-7000-8000-000000000001")
+	customerID := uuid.MustParse("0190a1b2-0000-7000-8000-000000000002")
+	itemID := uuid.MustParse("0190a1b2-0000-7000-8000-000000000003")
+	productID := uuid.MustParse("0190a1b2-0000-7000-8000-000000000004")
+	price := mustMoney(t, 1000)
+	newPrice := mustMoney(t, 1200)
+	zero := mustMoney(t, 0)
+
+	for name, testCase := range map[string]struct {
+		event    service.Event
+		expected string
+	}{
+		"OrderCreated": {
+			event:    model.OrderCreated{OrderID: orderID, CustomerID: customerID},
+			expected: fmt.Sprintf(`{"order_id":%q,"customer_id":%q}`, orderID, customerID),
+		},
+		"OrderItemChanged": {
+			event: model.OrderItemChanged{
+				OrderID:         orderID,
+				AddedItems:      []uuid.UUID{itemID},
+				QuantityChanges: []model.ItemQuantityChange{{ItemID: itemID, ProductID: productID, Delta: 2}},
+			},
+			expected: fmt.Sprintf(
+				`{"order_id":%q,"added_items":[%q],"quantity_changes":[{"item_id":%q,"product_id":%q,"delta":2}]}`,
+				orderID, itemID, itemID, productID,
+			),
+		},
+		"OrderStatusChanged": {
+			event:    model.OrderStatusChanged{OrderID: orderID, OldStatus: model.Open, NewStatus: model.Pending},
+			expected: fmt.Sprintf(`{"order_id":%q,"old_status":"Open","new_status":"Pending"}`, orderID),
+		},
+		"OrderRepriced": {
+			event: model.OrderRepriced{
+				OrderID:      orderID,
+				PriceChanges: []model.ItemPriceChange{{ItemID: itemID, ProductID: productID, OldPrice: price, NewPrice: newPrice}},
+			},
+			expected: fmt.Sprintf(
+				`{"order_id":%q,"price_changes":[{"item_id":%q,"product_id":%q,`+
+					`"old_price":{"amount":1000,"currency":"RUB"},"new_price":{"amount":1200,"currency":"RUB"}}]}`,
+				orderID, itemID, productID,
+			),
+		},
+		"OrderCancelled": {
+			event: model.OrderCancelled{
+				OrderID:    orderID,
+				CustomerID: customerID,
+				OldStatus:  model.Pending,
+				Reason:     model.OutOfStock,
+				Actor:      "warehouse",
+				Items:      []model.Item{{ID: itemID, OrderID: orderID, ProductID: productID, Price: price, Quantity: 2}},
+				Totals:     []model.OrderTotal{{Subtotal: newPrice, Discount: zero, Tax: zero, Total: newPrice}},
+			},
+			expected: fmt.Sprintf(
+				`{"order_id":%q,"customer_id":%q,"old_status":"Pending","reason":"OutOfStock","actor":"warehouse",`+
+					`"items":[{"item_id":%q,"product_id":%q,"price":{"amount":1000,"currency":"RUB"},"quantity":2}],`+
+					`"totals":[{"subtotal":{"amount":1200,"currency":"RUB"},"discount":{"amount":0,"currency":"RUB"},`+
+					`"tax":{"amount":0,"currency":"RUB"},"total":{"amount":1200,"currency":"RUB"}}]}`,
+				orderID, customerID, itemID, productID,
+			),
+		},
+		"OrderDeleted": {
+			event:    model.OrderDeleted{OrderID: orderID, CustomerID: customerID},
+			expected: fmt.Sprintf(`{"order_id":%q,"customer_id":%q}`, orderID, customerID),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			payload, err := NewEventSerializer().Serialize(testCase.event)
+			require.NoError(t, err)
+			require.JSONEq(t, testCase.expected, payload)
+		})
+	}
+
+	t.Run("Unknown event", func(t *testing.T) {
+		_, err := NewEventSerializer().Serialize(unknownEvent{})
+		require.ErrorIs(t, err, ErrUnknownEvent)
+	})
+}
+
+type unknownEvent struct{}
+
+func (unknownEvent) Type() string {
+	return "Unknown"
+}
+
+func mustMoney(t *testing.T, amount int64) model.Money {
+	t.Helper()
+	money, err := model.NewMoney(amount, "RUB")
+	require.NoError(t, err)
+	return money
+}
@@ -0,0 +1,96 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+
+	"order/pkg/application/service"
+)
+
+func NewIdempotencyRepository(ctx context.Context, client mysql.ClientContext) service.IdempotencyRepository {
+	return &idempotencyRepository{
+		ctx:    ctx,
+		client: client,
+	}
+}
+
+type idempotencyRepository struct {
+	ctx    context.Context
+	client mysql.ClientContext
+}
+
+func (r idempotencyRepository) Find(key string) (*service.IdempotencyRecord, error) {
+	const findRecord = `
+		SELECT
+			idempotency_key,
+			request_hash,
+			completed,
+			response,
+			expires_at
+		FROM idempotency_key
+		WHERE idempotency_key = ?
+		FOR UPDATE
+	`
+	var record sqlxIdempotencyRecord
+	err := r.client.GetContext(r.ctx, &record, findRecord, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.IdempotencyRecord{
+		Key:         record.Key,
+		RequestHash: record.RequestHash,
+		Completed:   record.Completed,
+		Response:    record.Response,
+		ExpiresAt:   record.ExpiresAt,
+	}, nil
+}
+
+func (r idempotencyRepository) Store(record service.IdempotencyRecord) error {
+	const storeRecord = `
+		INSERT INTO idempotency_key (
+			idempotency_key,
+			request_hash,
+			completed,
+			response,
+			expires_at
+		) VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			request_hash=VALUES(request_hash),
+			completed=VALUES(completed),
+			response=VALUES(response),
+			expires_at=VALUES(expires_at)
+	`
+	_, err := r.client.ExecContext(
+		r.ctx, storeRecord,
+		record.Key, record.RequestHash, record.Completed, record.Response, record.ExpiresAt,
+	)
+	return err
+}
+
+func (r idempotencyRepository) Delete(key string) error {
+	const deleteRecord = `DELETE FROM idempotency_key WHERE idempotency_key = ?`
+	_, err := r.client.ExecContext(r.ctx, deleteRecord, key)
+	return err
+}
+
+func (r idempotencyRepository) DeleteExpired(before time.Time) error {
+	const deleteExpired = `DELETE FROM idempotency_key WHERE expires_at < ?`
+	_, err := r.client.ExecContext(r.ctx, deleteExpired, before)
+	return err
+}
+
+type sqlxIdempotencyRecord struct {
+	Key         string    `db:"idempotency_key"`
+	RequestHash []byte    `db:"request_hash"`
+	Completed   bool      `db:"completed"`
+	Response    []byte    `db:"response"`
+	ExpiresAt   time.Time `db:"expires_at"`
+}
@@ -0,0 +1,78 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/application/service"
+)
+
+func newMockClient(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, mock.ExpectationsWereMet())
+		_ = db.Close()
+	})
+	return sqlx.NewDb(db, "mysql"), mock
+}
+
+func TestIdempotencyRepository(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	record := service.IdempotencyRecord{
+		Key:         "key",
+		RequestHash: []byte("hash"),
+		Completed:   true,
+		Response:    []byte("response"),
+		ExpiresAt:   expiresAt,
+	}
+
+	t.Run("Find locks the record", func(t *testing.T) {
+		client, mock := newMockClient(t)
+		mock.ExpectQuery(`SELECT .* FROM idempotency_key WHERE idempotency_key = \? FOR UPDATE`).
+			WithArgs("key").
+			WillReturnRows(sqlmock.NewRows([]string{"idempotency_key", "request_hash", "completed", "response", "expires_at"}).
+				AddRow(record.Key, record.RequestHash, record.Completed, record.Response, record.ExpiresAt))
+
+		found, err := NewIdempotencyRepository(context.Background(), client).Find("key")
+		require.NoError(t, err)
+		require.Equal(t, &record, found)
+	})
+
+	t.Run("Find returns nil for unknown key", func(t *testing.T) {
+		client, mock := newMockClient(t)
+		mock.ExpectQuery(`SELECT .* FROM idempotency_key`).
+			WithArgs("unknown").
+			WillReturnRows(sqlmock.NewRows([]string{"idempotency_key", "request_hash", "completed", "response", "expires_at"}))
+
+		found, err := NewIdempotencyRepository(context.Background(), client).Find("unknown")
+		require.NoError(t, err)
+		require.Nil(t, found)
+	})
+
+	t.Run("Store upserts the record", func(t *testing.T) {
+		client, mock := newMockClient(t)
+		mock.ExpectExec(`INSERT INTO idempotency_key .* ON DUPLICATE KEY UPDATE`).
+			WithArgs(record.Key, record.RequestHash, record.Completed, record.Response, record.ExpiresAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, NewIdempotencyRepository(context.Background(), client).Store(record))
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		client, mock := newMockClient(t)
+		now := time.Now()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM idempotency_key WHERE expires_at < ?`)).
+			WithArgs(now).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		require.NoError(t, NewIdempotencyRepository(context.Background(), client).DeleteExpired(now))
+	})
+}
@@ -0,0 +1,111 @@
+package mysql
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"order/pkg/domain/model"
+)
+
+// itemsBatchSize keeps multi-row statements far below the MySQL limit of 65535 placeholders
+const itemsBatchSize = 500
+
+type itemsDiff struct {
+	// upserted are new and changed items, both are written by a single INSERT ... ON DUPLICATE KEY UPDATE
+	upserted []model.Item
+	removed  []uuid.UUID
+}
+
+func diffItems(previous, current []model.Item) itemsDiff {
+	previousByID := make(map[uuid.UUID]model.Item, len(previous))
+	for _, item := range previous {
+		previousByID[item.ID] = item
+	}
+
+	var diff itemsDiff
+	for _, item := range current {
+		previousItem, ok := previousByID[item.ID]
+		delete(previousByID, item.ID)
+		if ok && previousItem == item {
+			continue
+		}
+		diff.upserted = append(diff.upserted, item)
+	}
+	for _, item := range previous {
+		if _, ok := previousByID[item.ID]; ok {
+			diff.removed = append(diff.removed, item.ID)
+		}
+	}
+	return diff
+}
+
+// storeItems writes only the difference between items and the previously loaded state of the order,
+// when the order was not loaded by this repository its stored items are read first
+func (o orderRepository) storeItems(orderID uuid.UUID, isNew bool, items []model.Item) error {
+	previous, ok := o.loadedItems[orderID]
+	if !ok && !isNew {
+		storedItems, err := o.findItems([]uuid.UUID{orderID})
+		if err != nil {
+			return err
+		}
+		previous = storedItems[orderID]
+	}
+
+	diff := diffItems(previous, items)
+	for batch := range slices.Chunk(diff.removed, itemsBatchSize) {
+		err := o.deleteItems(batch)
+		if err != nil {
+			return err
+		}
+	}
+	for batch := range slices.Chunk(diff.upserted, itemsBatchSize) {
+		err := o.upsertItems(orderID, batch)
+		if err != nil {
+			return err
+		}
+	}
+
+	o.loadedItems[orderID] = slices.Clone(items)
+	return nil
+}
+
+func (o orderRepository) deleteItems(itemIDs []uuid.UUID) error {
+	const deleteItems = `DELETE FROM item WHERE item_id IN (?)`
+	query, args, err := sqlx.In(deleteItems, itemIDs)
+	if err != nil {
+		return err
+	}
+	_, err = o.client.ExecContext(o.ctx, query, args...)
+	return err
+}
+
+func (o orderRepository) upsertItems(orderID uuid.UUID, items []model.Item) error {
+	const upsertItems = `
+		INSERT INTO item (
+			item_id,
+			order_id,
+			product_id,
+			price,
+			currency,
+			quantity
+		) VALUES %s
+		ON DUPLICATE KEY UPDATE
+			product_id=VALUES(product_id),
+			price=VALUES(price),
+			currency=VALUES(currency),
+			quantity=VALUES(quantity)
+	`
+	values := make([]string, 0, len(items))
+	args := make([]interface{}, 0, len(items)*6)
+	for _, item := range items {
+		values = append(values, "(?, ?, ?, ?, ?, ?)")
+		args = append(args, item.ID, orderID, item.ProductID, item.Price.Amount(), item.Price.Currency(), item.Quantity)
+	}
+
+	_, err := o.client.ExecContext(o.ctx, fmt.Sprintf(upsertItems, strings.Join(values, ", ")), args...)
+	return err
+}
@@ -0,0 +1,189 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/domain/model"
+)
+
+func TestDiffItems(t *testing.T) {
+	orderID := uuid.Must(uuid.NewV7())
+	previous := makeItems(t, orderID, 3)
+
+	current := []model.Item{previous[0], previous[1], makeItems(t, orderID, 1)[0]}
+	current[1].Quantity = 5
+
+	diff := diffItems(previous, current)
+	require.Equal(t, []model.Item{current[1], current[2]}, diff.upserted)
+	require.Equal(t, []uuid.UUID{previous[2].ID}, diff.removed)
+
+	require.Empty(t, diffItems(current, current))
+}
+
+func TestStoreItems(t *testing.T) {
+	orderID := uuid.Must(uuid.NewV7())
+	items := makeItems(t, orderID, itemsBatchSize+1)
+	client := &recordingClient{}
+	repo := NewOrderRepository(context.Background(), client).(*orderRepository)
+
+	t.Run("New order items are inserted in batches", func(t *testing.T) {
+		require.NoError(t, repo.storeItems(orderID, true, items))
+		require.Equal(t, 2, client.statements)
+		require.Equal(t, len(items)*6, client.args)
+	})
+
+	t.Run("Unchanged items are not written", func(t *testing.T) {
+		client.reset()
+		require.NoError(t, repo.storeItems(orderID, false, items))
+		require.Zero(t, client.statements)
+	})
+
+	t.Run("Changed and removed items are written", func(t *testing.T) {
+		client.reset()
+		changed := append([]model.Item(nil), items[1:]...)
+		changed[0].Quantity++
+		require.NoError(t, repo.storeItems(orderID, false, changed))
+		require.Equal(t, 2, client.statements)
+	})
+
+	t.Run("Stored items are read when order was not loaded", func(t *testing.T) {
+		client.reset()
+		client.storedItems = items[:1]
+		repo := NewOrderRepository(context.Background(), client).(*orderRepository)
+		require.NoError(t, repo.storeItems(orderID, false, items[:1]))
+		require.Equal(t, 1, client.selects)
+		require.Zero(t, client.statements)
+	})
+}
+
+// BenchmarkStoreItems compares statements sent to MySQL when one item of a large order changes,
+// every statement is a network round trip and an InnoDB undo log record per affected row
+func BenchmarkStoreItems(b *testing.B) {
+	orderID := uuid.Must(uuid.NewV7())
+	for _, size := range []int{10, 100, 1000} {
+		items := makeItems(b, orderID, size)
+
+		b.Run(fmt.Sprintf("reinsert/%d", size), func(b *testing.B) {
+			client := &recordingClient{}
+			repo := NewOrderRepository(context.Background(), client).(*orderRepository)
+			for i := 0; i < b.N; i++ {
+				items[0].Quantity++
+				require.NoError(b, reinsertItems(repo, orderID, items))
+			}
+			client.report(b)
+		})
+
+		b.Run(fmt.Sprintf("diff/%d", size), func(b *testing.B) {
+			client := &recordingClient{}
+			repo := NewOrderRepository(context.Background(), client).(*orderRepository)
+			repo.loadedItems[orderID] = append([]model.Item(nil), items...)
+			for i := 0; i < b.N; i++ {
+				items[0].Quantity++
+				require.NoError(b, repo.storeItems(orderID, false, items))
+			}
+			client.report(b)
+		})
+	}
+}
+
+// reinsertItems is the former strategy: delete all items and insert them one by one
+func reinsertItems(repo *orderRepository, orderID uuid.UUID, items []model.Item) error {
+	_, err := repo.client.ExecContext(repo.ctx, `DELETE FROM item WHERE order_id = ?`, orderID)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		_, err = repo.client.ExecContext(
+			repo.ctx, `INSERT INTO item (item_id, order_id, product_id, price, currency, quantity) VALUES (?, ?, ?, ?, ?, ?)`,
+			item.ID, orderID, item.ProductID, item.Price.Amount(), item.Price.Currency(), item.Quantity,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func makeItems(tb testing.TB, orderID uuid.UUID, count int) []model.Item {
+	tb.Helper()
+	price, err := model.NewMoney(100, "USD")
+	require.NoError(tb, err)
+
+	items := make([]model.Item, 0, count)
+	for i := 0; i < count; i++ {
+		items = append(items, model.Item{
+			ID:        uuid.Must(uuid.NewV7()),
+			OrderID:   orderID,
+			ProductID: uuid.Must(uuid.NewV7()),
+			Price:     price,
+			Quantity:  1,
+		})
+	}
+	return items
+}
+
+type recordingClient struct {
+	statements  int
+	args        int
+	selects     int
+	storedItems []model.Item
+}
+
+func (c *recordingClient) reset() {
+	*c = recordingClient{}
+}
+
+func (c *recordingClient) report(b *testing.B) {
+	b.ReportMetric(float64(c.statements)/float64(b.N), "statements/op")
+	b.ReportMetric(float64(c.args)/float64(b.N), "args/op")
+}
+
+func (c *recordingClient) ExecContext(_ context.Context, _ string, args ...interface{}) (sql.Result, error) {
+	c.statements++
+	c.args += len(args)
+	return driverResult(0), nil
+}
+
+func (c *recordingClient) SelectContext(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+	c.selects++
+	rows := dest.(*[]sqlxItem)
+	for _, item := range c.storedItems {
+		*rows = append(*rows, sqlxItem{
+			ID:        item.ID,
+			OrderID:   item.OrderID,
+			ProductID: item.ProductID,
+			Price:     item.Price.Amount(),
+			Currency:  item.Price.Currency(),
+			Quantity:  item.Quantity,
+		})
+	}
+	return nil
+}
+
+func (c *recordingClient) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	panic("unexpected query")
+}
+
+func (c *recordingClient) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	panic("unexpected query")
+}
+
+func (c *recordingClient) GetContext(context.Context, interface{}, string, ...interface{}) error {
+	panic("unexpected query")
+}
+
+type driverResult int64
+
+func (r driverResult) LastInsertId() (int64, error) {
+	return 0, nil
+}
+
+func (r driverResult) RowsAffected() (int64, error) {
+	return int64(r), nil
+}
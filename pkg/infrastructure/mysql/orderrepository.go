@@ -3,27 +3,31 @@ package mysql
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 
 	"order/pkg/domain/model"
 )
 
 func NewOrderRepository(ctx context.Context, client mysql.ClientContext) model.OrderRepository {
 	return &orderRepository{
-		ctx:    ctx,
-		client: client,
+		ctx:         ctx,
+		client:      client,
+		loadedItems: make(map[uuid.UUID][]model.Item),
 	}
 }
 
 type orderRepository struct {
 	ctx    context.Context
 	client mysql.ClientContext
+	// loadedItems keeps items as they were loaded or stored, Store writes only the difference
+	loadedItems map[uuid.UUID][]model.Item
 }
 
 func (o orderRepository) NextID() (uuid.UUID, error) {
@@ -31,56 +35,95 @@ func (o orderRepository) NextID() (uuid.UUID, error) {
 }
 
 func (o orderRepository) Store(order *model.Order) error {
+	isNew := order.Version == 0
 	err := o.storeOrder(order)
 	if err != nil {
 		return err
 	}
 
-	err = o.storeItems(order.ID, order.Items)
+	err = o.storeItems(order.ID, isNew, order.Items)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return o.storeTotals(order.ID, order.Totals)
 }
 
+// storeOrder inserts a new order or updates the stored one only if it still has the version the order was loaded with
 func (o orderRepository) storeOrder(order *model.Order) error {
-	const storeOrder = `
-		INSERT INTO order (
-			order_id,
-		    customer_id,
-		    status,
-		    created_at,
-		    updated_at,
-		    deleted_at
-		) VALUES (?, ?, ?, ?, ?, ?)
-		ON DUPLICATE KEY UPDATE
-			status=VALUES(status),
-			updated_at=VALUES(updated_at),
-			deleted_at=VALUES(deleted_at)
-	`
+	if order.Version == 0 {
+		const insertOrder = `
+			INSERT INTO orders (
+				order_id,
+				customer_id,
+				status,
+				version,
+				created_at,
+				updated_at,
+				deleted_at
+			) VALUES (?, ?, ?, 1, ?, ?, ?)
+		`
+		_, err := o.client.ExecContext(
+			o.ctx, insertOrder,
+			order.ID, order.CustomerID, order.Status, order.CreatedAt, order.UpdatedAt, order.DeletedAt,
+		)
+		if err != nil {
+			return err
+		}
+		order.Version = 1
+		return nil
+	}
 
-	_, err := o.client.ExecContext(
-		o.ctx, storeOrder,
-		order.ID, order.CustomerID, order.Status, order.CreatedAt, order.UpdatedAt, order.DeletedAt,
+	const updateOrder = `
+		UPDATE orders
+		SET
+			status = ?,
+			version = version + 1,
+			updated_at = ?,
+			deleted_at = COALESCE(deleted_at, ?)
+		WHERE order_id = ? AND version = ?
+	`
+	result, err := o.client.ExecContext(
+		o.ctx, updateOrder,
+		order.Status, order.UpdatedAt, order.DeletedAt, order.ID, order.Version,
 	)
-	return err
-}
+	if err != nil {
+		return err
+	}
 
-func (o orderRepository) storeItems(orderID uuid.UUID, items []model.Item) error {
-	const deleteItems = `DELETE FROM item WHERE order_id = ?`
-	_, err := o.client.ExecContext(o.ctx, deleteItems, orderID)
+	affected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
+	if affected == 0 {
+		return model.ErrConcurrentModification
+	}
+	order.Version++
+	return nil
+}
 
-	if len(items) == 0 {
-		return nil
+func (o orderRepository) storeTotals(orderID uuid.UUID, totals []model.OrderTotal) error {
+	const deleteTotals = `DELETE FROM order_total WHERE order_id = ?`
+	_, err := o.client.ExecContext(o.ctx, deleteTotals, orderID)
+	if err != nil {
+		return err
 	}
 
-	const storeItems = `INSERT INTO item (order_id, product_id, price) VALUES (?, ?, ?)`
-	for _, item := range items {
-		_, err = o.client.ExecContext(o.ctx, storeItems, orderID, item.ProductID, item.Price)
+	const storeTotal = `
+		INSERT INTO order_total (
+			order_id,
+			currency,
+			subtotal,
+			discount,
+			tax,
+			total
+		) VALUES (?, ?, ?, ?, ?, ?)
+	`
+	for _, total := range totals {
+		_, err = o.client.ExecContext(
+			o.ctx, storeTotal,
+			orderID, total.Currency(), total.Subtotal.Amount(), total.Discount.Amount(), total.Tax.Amount(), total.Total.Amount(),
+		)
 		if err != nil {
 			return err
 		}
@@ -90,44 +133,224 @@ func (o orderRepository) storeItems(orderID uuid.UUID, items []model.Item) error
 }
 
 func (o orderRepository) Find(spec model.FindSpec) (*model.Order, error) {
-	const findItems = `
+	// order and its items are loaded in a single round trip, order columns are repeated for every item row
+	const findOrder = `
+		SELECT
+			o.order_id,
+			o.customer_id,
+			o.status,
+			o.version,
+			o.created_at,
+			o.updated_at,
+			o.deleted_at,
+			i.item_id,
+			i.product_id,
+			i.price,
+			i.currency,
+			i.quantity
+		FROM (
+			SELECT
+				order_id,
+				customer_id,
+				status,
+				version,
+				created_at,
+				updated_at,
+				deleted_at
+			FROM orders
+			WHERE %s
+			ORDER BY order_id
+			LIMIT 1
+		) o
+		LEFT JOIN item i ON i.order_id = o.order_id
+		ORDER BY i.item_id
+	`
+	whereQuery, args := o.buildWhereConditions(spec)
+
+	var rows []sqlxOrderItemRow
+	err := o.selectIn(&rows, fmt.Sprintf(findOrder, whereQuery), args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, model.ErrOrderNotFound
+	}
+
+	order := rows[0].sqlxOrder
+	var items []model.Item
+	for _, row := range rows {
+		if !row.ItemID.Valid {
+			continue
+		}
+		price, err2 := model.NewMoney(row.Price.Int64, row.Currency.String)
+		if err2 != nil {
+			return nil, err2
+		}
+		items = append(items, model.Item{
+			ID:        row.ItemID.UUID,
+			OrderID:   order.ID,
+			ProductID: row.ProductID.UUID,
+			Price:     price,
+			Quantity:  int(row.Quantity.Int32),
+		})
+	}
+
+	totals, err := o.findTotals([]uuid.UUID{order.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	o.loadedItems[order.ID] = slices.Clone(items)
+	result := order.toModel(items, totals[order.ID])
+	return &result, nil
+}
+
+func (o orderRepository) List(spec model.FindSpec, page model.Page) ([]model.Order, error) {
+	const listOrders = `
 		SELECT
 			order_id,
 			customer_id,
 			status,
+			version,
 			created_at,
 			updated_at,
 			deleted_at
-		FROM order
-		WHERE %s
+		FROM orders
+		WHERE %s AND order_id > ?
+		ORDER BY order_id
+		LIMIT ?
 	`
 	whereQuery, args := o.buildWhereConditions(spec)
+	args = append(args, page.After, page.Limit)
 
-	var order sqlxOrder
-	err := o.client.GetContext(o.ctx, &order, fmt.Sprintf(findItems, whereQuery), args...)
+	var rows []sqlxOrder
+	err := o.selectIn(&rows, fmt.Sprintf(listOrders, whereQuery), args...)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, model.ErrOrderNotFound
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	orderIDs := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		orderIDs = append(orderIDs, row.ID)
+	}
+	items, err := o.findItems(orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := o.findTotals(orderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]model.Order, 0, len(rows))
+	for _, row := range rows {
+		o.loadedItems[row.ID] = slices.Clone(items[row.ID])
+		orders = append(orders, row.toModel(items[row.ID], totals[row.ID]))
+	}
+	return orders, nil
+}
+
+func (o orderRepository) findItems(orderIDs []uuid.UUID) (map[uuid.UUID][]model.Item, error) {
+	const findItems = `
+		SELECT
+			item_id,
+			order_id,
+			product_id,
+			price,
+			currency,
+			quantity
+		FROM item
+		WHERE order_id IN (?)
+		ORDER BY item_id
+	`
+	var rows []sqlxItem
+	err := o.selectIn(&rows, findItems, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[uuid.UUID][]model.Item, len(orderIDs))
+	for _, row := range rows {
+		price, err2 := model.NewMoney(row.Price, row.Currency)
+		if err2 != nil {
+			return nil, err2
 		}
+		items[row.OrderID] = append(items[row.OrderID], model.Item{
+			ID:        row.ID,
+			OrderID:   row.OrderID,
+			ProductID: row.ProductID,
+			Price:     price,
+			Quantity:  row.Quantity,
+		})
+	}
+	return items, nil
+}
+
+func (o orderRepository) findTotals(orderIDs []uuid.UUID) (map[uuid.UUID][]model.OrderTotal, error) {
+	const findTotals = `
+		SELECT
+			order_id,
+			currency,
+			subtotal,
+			discount,
+			tax,
+			total
+		FROM order_total
+		WHERE order_id IN (?)
+		ORDER BY currency
+	`
+	var rows []sqlxOrderTotal
+	err := o.selectIn(&rows, findTotals, orderIDs)
+	if err != nil {
 		return nil, err
 	}
 
-	// TODO load items
+	totals := make(map[uuid.UUID][]model.OrderTotal, len(orderIDs))
+	for _, row := range rows {
+		total, err2 := row.toModel()
+		if err2 != nil {
+			return nil, err2
+		}
+		totals[row.OrderID] = append(totals[row.OrderID], total)
+	}
+	return totals, nil
+}
 
-	return &model.Order{
-		ID:         order.ID,
-		CustomerID: order.CustomerID,
-		Status:     model.OrderStatus(order.Status),
-		Items:      nil,
-		CreatedAt:  order.CreatedAt,
-		UpdatedAt:  order.UpdatedAt,
-		DeletedAt:  order.DeletedAt,
-	}, nil
+// selectIn expands slice arguments into IN lists
+func (o orderRepository) selectIn(dest interface{}, query string, args ...interface{}) error {
+	query, args, err := sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	return o.client.SelectContext(o.ctx, dest, query, args...)
 }
 
 func (o orderRepository) Delete(id uuid.UUID) error {
-	//TODO implement me
-	panic("implement me")
+	const deleteOrder = `
+		UPDATE orders
+		SET
+			deleted_at = ?,
+			updated_at = ?,
+			version = version + 1
+		WHERE order_id = ? AND deleted_at IS NULL
+	`
+	currentTime := time.Now()
+	result, err := o.client.ExecContext(o.ctx, deleteOrder, currentTime, currentTime, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return model.ErrOrderNotFound
+	}
+	return nil
 }
 
 func (o orderRepository) buildWhereConditions(spec model.FindSpec) (query string, args []interface{}) {
@@ -140,13 +363,24 @@ func (o orderRepository) buildWhereConditions(spec model.FindSpec) (query string
 		parts = append(parts, "customer_id = ?")
 		args = append(args, *spec.CustomerID)
 	}
-	if spec.Status != nil {
-		parts = append(parts, "status = ?")
-		args = append(args, *spec.Status)
+	if len(spec.Statuses) > 0 {
+		parts = append(parts, "status IN (?)")
+		args = append(args, spec.Statuses)
+	}
+	if spec.CreatedFrom != nil {
+		parts = append(parts, "created_at >= ?")
+		args = append(args, *spec.CreatedFrom)
+	}
+	if spec.CreatedTo != nil {
+		parts = append(parts, "created_at < ?")
+		args = append(args, *spec.CreatedTo)
 	}
 	if !spec.IncludeDeleted {
 		parts = append(parts, "deleted_at is null")
 	}
+	if len(parts) == 0 {
+		return "TRUE", nil
+	}
 	return strings.Join(parts, " AND "), args
 }
 
@@ -154,13 +388,71 @@ type sqlxOrder struct {
 	ID         uuid.UUID  `db:"order_id"`
 	CustomerID uuid.UUID  `db:"customer_id"`
 	Status     int        `db:"status"`
+	Version    int64      `db:"version"`
 	CreatedAt  time.Time  `db:"created_at"`
 	UpdatedAt  time.Time  `db:"updated_at"`
 	DeletedAt  *time.Time `db:"deleted_at"`
 }
 
+func (o sqlxOrder) toModel(items []model.Item, totals []model.OrderTotal) model.Order {
+	return model.Order{
+		ID:         o.ID,
+		CustomerID: o.CustomerID,
+		Status:     model.OrderStatus(o.Status),
+		Version:    o.Version,
+		Items:      items,
+		Totals:     totals,
+		CreatedAt:  o.CreatedAt,
+		UpdatedAt:  o.UpdatedAt,
+		DeletedAt:  o.DeletedAt,
+	}
+}
+
+type sqlxOrderItemRow struct {
+	sqlxOrder
+	ItemID    uuid.NullUUID  `db:"item_id"`
+	ProductID uuid.NullUUID  `db:"product_id"`
+	Price     sql.NullInt64  `db:"price"`
+	Currency  sql.NullString `db:"currency"`
+	Quantity  sql.NullInt32  `db:"quantity"`
+}
+
 type sqlxItem struct {
+	ID        uuid.UUID `db:"item_id"`
 	OrderID   uuid.UUID `db:"order_id"`
 	ProductID uuid.UUID `db:"product_id"`
-	Price     float64   `db:"price"`
+	Price     int64     `db:"price"`
+	Currency  string    `db:"currency"`
+	Quantity  int       `db:"quantity"`
+}
+
+type sqlxOrderTotal struct {
+	OrderID  uuid.UUID `db:"order_id"`
+	Currency string    `db:"currency"`
+	Subtotal int64     `db:"subtotal"`
+	Discount int64     `db:"discount"`
+	Tax      int64     `db:"tax"`
+	Total    int64     `db:"total"`
+}
+
+func (t sqlxOrderTotal) toModel() (model.OrderTotal, error) {
+	var (
+		total model.OrderTotal
+		err   error
+	)
+	for _, field := range []struct {
+		dst    *model.Money
+		amount int64
+	}{
+		{&total.Subtotal, t.Subtotal},
+		{&total.Discount, t.Discount},
+		{&total.Tax, t.Tax},
+		{&total.Total, t.Total},
+	} {
+		*field.dst, err = model.NewMoney(field.amount, t.Currency)
+		if err != nil {
+			return model.OrderTotal{}, err
+		}
+	}
+	return total, nil
 }
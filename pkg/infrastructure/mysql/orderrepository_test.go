@@ -0,0 +1,96 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/domain/model"
+)
+
+func TestStoreOrderVersion(t *testing.T) {
+	newOrder := func(version int64) *model.Order {
+		return &model.Order{
+			ID:         uuid.Must(uuid.NewV7()),
+			CustomerID: uuid.Must(uuid.NewV7()),
+			Status:     model.Pending,
+			Version:    version,
+			UpdatedAt:  time.Now(),
+		}
+	}
+
+	t.Run("New order is inserted with the first version", func(t *testing.T) {
+		client, mock := newMockClient(t)
+		order := newOrder(0)
+		mock.ExpectExec(`INSERT INTO orders .* VALUES \(\?, \?, \?, 1, \?, \?, \?\)`).
+			WithArgs(order.ID, order.CustomerID, order.Status, order.CreatedAt, order.UpdatedAt, order.DeletedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, orderRepository{ctx: context.Background(), client: client}.storeOrder(order))
+		require.Equal(t, int64(1), order.Version)
+	})
+
+	t.Run("Update is conditional on the loaded version", func(t *testing.T) {
+		client, mock := newMockClient(t)
+		order := newOrder(3)
+		mock.ExpectExec(`UPDATE orders .* WHERE order_id = \? AND version = \?`).
+			WithArgs(order.Status, order.UpdatedAt, order.DeletedAt, order.ID, int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, orderRepository{ctx: context.Background(), client: client}.storeOrder(order))
+		require.Equal(t, int64(4), order.Version)
+	})
+
+	t.Run("Stale version is a concurrent modification", func(t *testing.T) {
+		client, mock := newMockClient(t)
+		order := newOrder(3)
+		mock.ExpectExec(`UPDATE orders .* WHERE order_id = \? AND version = \?`).
+			WithArgs(order.Status, order.UpdatedAt, order.DeletedAt, order.ID, int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := orderRepository{ctx: context.Background(), client: client}.storeOrder(order)
+		require.ErrorIs(t, err, model.ErrConcurrentModification)
+		require.Equal(t, int64(3), order.Version)
+	})
+}
+
+func TestListOrders(t *testing.T) {
+	customerID := uuid.Must(uuid.NewV7())
+	after := uuid.Must(uuid.NewV7())
+	spec := model.FindSpec{CustomerID: &customerID}
+
+	t.Run("Page continues after the last order", func(t *testing.T) {
+		client, mock := newMockClient(t)
+		orderID := uuid.Must(uuid.NewV7())
+		mock.ExpectQuery(`SELECT .* FROM orders WHERE customer_id = \? AND deleted_at is null AND order_id > \? ORDER BY order_id LIMIT \?`).
+			WithArgs(customerID, after, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "customer_id", "status", "version", "created_at", "updated_at", "deleted_at"}).
+				AddRow(orderID, customerID, model.Open, 1, time.Now(), time.Now(), nil))
+		mock.ExpectQuery(`SELECT .* FROM item WHERE order_id IN \(\?\)`).
+			WithArgs(orderID).
+			WillReturnRows(sqlmock.NewRows([]string{"item_id", "order_id", "product_id", "price", "currency", "quantity"}))
+		mock.ExpectQuery(`SELECT .* FROM order_total WHERE order_id IN \(\?\)`).
+			WithArgs(orderID).
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "currency", "subtotal", "discount", "tax", "total"}))
+
+		orders, err := NewOrderRepository(context.Background(), client).List(spec, model.Page{After: after, Limit: 3})
+		require.NoError(t, err)
+		require.Len(t, orders, 1)
+		require.Equal(t, orderID, orders[0].ID)
+	})
+
+	t.Run("Empty page does not load items", func(t *testing.T) {
+		client, mock := newMockClient(t)
+		mock.ExpectQuery(`SELECT .* FROM orders`).
+			WithArgs(customerID, after, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "customer_id", "status", "version", "created_at", "updated_at", "deleted_at"}))
+
+		orders, err := NewOrderRepository(context.Background(), client).List(spec, model.Page{After: after, Limit: 3})
+		require.NoError(t, err)
+		require.Empty(t, orders)
+	})
+}
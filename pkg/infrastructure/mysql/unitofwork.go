@@ -0,0 +1,118 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	mysqldriver "github.com/go-sql-driver/mysql"
+
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+)
+
+const (
+	errDeadlock        = 1213
+	errLockWaitTimeout = 1205
+
+	retryBackoff = 50 * time.Millisecond
+)
+
+func NewRepositoryProvider(client mysql.ClientContext) service.RepositoryProvider {
+	return &repositoryProvider{
+		client: client,
+	}
+}
+
+type repositoryProvider struct {
+	client mysql.ClientContext
+}
+
+func (r repositoryProvider) OrderRepository(ctx context.Context) model.OrderRepository {
+	return NewOrderRepository(ctx, r.client)
+}
+
+func (r repositoryProvider) IdempotencyRepository(ctx context.Context) service.IdempotencyRepository {
+	return NewIdempotencyRepository(ctx, r.client)
+}
+
+// NewUnitOfWork runs callbacks in a transaction and retries the whole transaction when MySQL reports a deadlock
+func NewUnitOfWork(
+	uow mysql.UnitOfWorkWithRepositoryProvider[service.RepositoryProvider],
+	maxRetries int,
+) service.UnitOfWork {
+	return &unitOfWork{
+		uow:        uow,
+		maxRetries: maxRetries,
+	}
+}
+
+type unitOfWork struct {
+	uow        mysql.UnitOfWorkWithRepositoryProvider[service.RepositoryProvider]
+	maxRetries int
+}
+
+func (u unitOfWork) Execute(ctx context.Context, f func(provider service.RepositoryProvider) error) error {
+	ctx = service.TransactionContext(ctx)
+	return executeWithRetry(ctx, u.maxRetries, func() error {
+		return u.uow.ExecuteWithRepositoryProvider(ctx, f)
+	})
+}
+
+// NewLockableUnitOfWork takes a named MySQL lock (GET_LOCK) for the duration of the transaction
+func NewLockableUnitOfWork(
+	luow mysql.LockableUnitOfWorkWithRepositoryProvider[service.RepositoryProvider],
+	lockTimeout time.Duration,
+	maxRetries int,
+) service.LockableUnitOfWork {
+	return &lockableUnitOfWork{
+		luow:        luow,
+		lockTimeout: lockTimeout,
+		maxRetries:  maxRetries,
+	}
+}
+
+type lockableUnitOfWork struct {
+	luow        mysql.LockableUnitOfWorkWithRepositoryProvider[service.RepositoryProvider]
+	lockTimeout time.Duration
+	maxRetries  int
+}
+
+func (u lockableUnitOfWork) Execute(ctx context.Context, lockName string, f func(provider service.RepositoryProvider) error) error {
+	ctx = service.TransactionContext(ctx)
+	return executeWithRetry(ctx, u.maxRetries, func() error {
+		return u.luow.ExecuteWithRepositoryProvider(ctx, lockName, u.lockTimeout, f)
+	})
+}
+
+// executeWithRetry retries only the outermost unit of work of a shared transaction,
+// a nested one can not be retried alone since MySQL rolls back the whole transaction on deadlock
+func executeWithRetry(ctx context.Context, maxRetries int, f func() error) error {
+	nested, exit := service.EnterTransaction(ctx)
+	if nested {
+		return f()
+	}
+	defer exit()
+
+	for attempt := 0; ; attempt++ {
+		err := f()
+		if err == nil || attempt >= maxRetries || !isRetryableError(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(retryBackoff * time.Duration(attempt+1)):
+		}
+	}
+}
+
+func isRetryableError(err error) bool {
+	var mysqlErr *mysqldriver.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == errDeadlock || mysqlErr.Number == errLockWaitTimeout
+}
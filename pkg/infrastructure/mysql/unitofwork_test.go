@@ -0,0 +1,194 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/mysql"
+	"github.com/DATA-DOG/go-sqlmock"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+)
+
+func TestExecuteWithRetry(t *testing.T) {
+	deadlock := &mysqldriver.MySQLError{Number: errDeadlock}
+	lockWaitTimeout := &mysqldriver.MySQLError{Number: errLockWaitTimeout}
+	noop := func(service.RepositoryProvider) error { return nil }
+
+	for name, retryable := range map[string]error{
+		"Deadlock":          deadlock,
+		"Lock wait timeout": lockWaitTimeout,
+	} {
+		t.Run(name+" is retried", func(t *testing.T) {
+			uow := &failingUnitOfWork{errs: []error{retryable, retryable}}
+			require.NoError(t, NewUnitOfWork(uow, 3).Execute(context.Background(), noop))
+			require.Equal(t, 3, uow.calls)
+		})
+	}
+
+	t.Run("Retries are bounded", func(t *testing.T) {
+		uow := &failingUnitOfWork{errs: []error{deadlock, deadlock, deadlock, deadlock}}
+		err := NewUnitOfWork(uow, 2).Execute(context.Background(), noop)
+		require.ErrorIs(t, err, deadlock)
+		require.Equal(t, 3, uow.calls)
+	})
+
+	for name, err := range map[string]error{
+		"Duplicate entry": &mysqldriver.MySQLError{Number: 1062},
+		"Domain error":    model.ErrOrderNotFound,
+	} {
+		t.Run(name+" is not retried", func(t *testing.T) {
+			uow := &failingUnitOfWork{errs: []error{err}}
+			require.ErrorIs(t, NewUnitOfWork(uow, 3).Execute(context.Background(), noop), err)
+			require.Equal(t, 1, uow.calls)
+		})
+	}
+
+	t.Run("Cancelled context stops retries", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		uow := &failingUnitOfWork{errs: []error{deadlock, deadlock}, onCall: cancel}
+		err := NewUnitOfWork(uow, 3).Execute(ctx, noop)
+		require.ErrorIs(t, err, deadlock)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 1, uow.calls)
+	})
+
+	t.Run("Lockable unit of work is retried", func(t *testing.T) {
+		luow := &failingLockableUnitOfWork{failingUnitOfWork{errs: []error{lockWaitTimeout}}}
+		require.NoError(t, NewLockableUnitOfWork(luow, time.Second, 3).Execute(context.Background(), "order", noop))
+		require.Equal(t, 2, luow.calls)
+	})
+
+	t.Run("Nested unit of work is retried with the outermost one", func(t *testing.T) {
+		ctx := service.WithSharedTransaction(context.Background())
+		outer, inner := 0, 0
+		err := executeWithRetry(ctx, 3, func() error {
+			outer++
+			return executeWithRetry(ctx, 3, func() error {
+				inner++
+				if inner == 1 {
+					return deadlock
+				}
+				return nil
+			})
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, outer)
+		require.Equal(t, 2, inner)
+	})
+
+	t.Run("Units of work sharing a context without shared transaction are retried separately", func(t *testing.T) {
+		ctx := context.Background()
+		outer, inner := 0, 0
+		err := executeWithRetry(ctx, 3, func() error {
+			outer++
+			return executeWithRetry(ctx, 3, func() error {
+				inner++
+				if inner == 1 {
+					return deadlock
+				}
+				return nil
+			})
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, outer)
+		require.Equal(t, 2, inner)
+	})
+}
+
+// failingUnitOfWork fails with errs one by one and then succeeds
+type failingUnitOfWork struct {
+	errs   []error
+	calls  int
+	onCall func()
+}
+
+func (u *failingUnitOfWork) ExecuteWithClientContext(context.Context, func(client mysql.ClientContext) error) error {
+	panic("unexpected call")
+}
+
+func (u *failingUnitOfWork) ExecuteWithRepositoryProvider(_ context.Context, callback func(provider service.RepositoryProvider) error) error {
+	u.calls++
+	if u.onCall != nil {
+		u.onCall()
+	}
+	if len(u.errs) > 0 {
+		err := u.errs[0]
+		u.errs = u.errs[1:]
+		return err
+	}
+	return callback(nil)
+}
+
+type failingLockableUnitOfWork struct {
+	failingUnitOfWork
+}
+
+func (u *failingLockableUnitOfWork) ExecuteWithClientContext(context.Context, string, time.Duration, func(client mysql.ClientContext) error) error {
+	panic("unexpected call")
+}
+
+func (u *failingLockableUnitOfWork) ExecuteWithRepositoryProvider(
+	ctx context.Context,
+	_ string,
+	_ time.Duration,
+	callback func(provider service.RepositoryProvider) error,
+) error {
+	return u.failingUnitOfWork.ExecuteWithRepositoryProvider(ctx, callback)
+}
+
+func TestSharedTransactionLocks(t *testing.T) {
+	client, mock := newMockClient(t)
+	pool := mysql.NewConnectionPool(sqlxTransactionalClient{client})
+	uow := mysql.NewUnitOfWork(pool, NewRepositoryProvider)
+	luow := NewLockableUnitOfWork(mysql.NewLockableUnitOfWork(uow, mysql.NewLocker(pool)), time.Second, 0)
+
+	lockRows := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"result"}).AddRow(1) }
+	mock.ExpectQuery(`SELECT GET_LOCK`).WithArgs("idempotency_key", 1).WillReturnRows(lockRows())
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT GET_LOCK`).WithArgs("customer", 1).WillReturnRows(lockRows())
+	mock.ExpectExec(`DELETE FROM idempotency_key`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT RELEASE_LOCK`).WithArgs("idempotency_key").WillReturnRows(lockRows())
+	mock.ExpectQuery(`SELECT RELEASE_LOCK`).WithArgs("customer").WillReturnRows(lockRows())
+
+	// lock of the nested unit of work is released only after the shared transaction is committed
+	ctx := service.WithSharedTransaction(context.Background())
+	err := luow.Execute(ctx, "idempotency_key", func(service.RepositoryProvider) error {
+		return luow.Execute(ctx, "customer", func(provider service.RepositoryProvider) error {
+			return provider.IdempotencyRepository(ctx).Delete("key")
+		})
+	})
+	require.NoError(t, err)
+}
+
+// sqlxTransactionalClient runs golib units of work over a mocked database
+type sqlxTransactionalClient struct {
+	*sqlx.DB
+}
+
+func (c sqlxTransactionalClient) BeginTransaction() (mysql.Transaction, error) {
+	return c.Beginx()
+}
+
+func (c sqlxTransactionalClient) Connection(ctx context.Context) (mysql.TransactionalConnection, error) {
+	conn, err := c.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sqlxConnection{conn}, nil
+}
+
+type sqlxConnection struct {
+	*sqlx.Conn
+}
+
+func (c sqlxConnection) BeginTransaction(ctx context.Context, opts *sql.TxOptions) (mysql.Transaction, error) {
+	return c.BeginTxx(ctx, opts)
+}
@@ -0,0 +1,137 @@
+package publisher
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var ErrMessageNotConfirmed = errors.New("message not confirmed by broker")
+
+const (
+	amqpExchangeKind = "topic"
+	causationHeader  = "causation_id"
+)
+
+// NewAMQPPublisher publishes messages to a durable topic exchange using event type as routing key.
+// Publisher confirms are enabled, so Publish returns only after the broker has taken responsibility for messages
+func NewAMQPPublisher(url, exchange string) (Publisher, error) {
+	p := &amqpPublisher{
+		url:      url,
+		exchange: exchange,
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.connect(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+type amqpPublisher struct {
+	url      string
+	exchange string
+
+	mu      sync.Mutex
+	conn    *amqp.Connection
+	channel *amqp.Channel
+}
+
+func (p *amqpPublisher) Publish(ctx context.Context, messages []Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn.IsClosed() || p.channel.IsClosed() {
+		_ = p.closeConnection()
+		if err := p.connect(); err != nil {
+			return err
+		}
+	}
+
+	confirmations := make([]*amqp.DeferredConfirmation, 0, len(messages))
+	for _, message := range messages {
+		confirmation, err := p.channel.PublishWithDeferredConfirmWithContext(
+			ctx,
+			p.exchange,
+			message.Type,
+			false,
+			false,
+			amqp.Publishing{
+				ContentType:   "application/json",
+				DeliveryMode:  amqp.Persistent,
+				MessageId:     message.MessageID,
+				CorrelationId: message.CorrelationID,
+				Headers:       causationHeaders(message.CausationID),
+				Type:          message.Type,
+				Timestamp:     time.Now(),
+				Body:          []byte(message.Payload),
+			},
+		)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		confirmations = append(confirmations, confirmation)
+	}
+
+	for _, confirmation := range confirmations {
+		acked, err := confirmation.WaitContext(ctx)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if !acked {
+			return errors.WithStack(ErrMessageNotConfirmed)
+		}
+	}
+	return nil
+}
+
+func (p *amqpPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.closeConnection()
+}
+
+func (p *amqpPublisher) connect() error {
+	conn, err := amqp.Dial(p.url)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to AMQP broker")
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return errors.WithStack(err)
+	}
+	if err = channel.Confirm(false); err != nil {
+		_ = conn.Close()
+		return errors.WithStack(err)
+	}
+	err = channel.ExchangeDeclare(p.exchange, amqpExchangeKind, true, false, false, false, nil)
+	if err != nil {
+		_ = conn.Close()
+		return errors.WithStack(err)
+	}
+
+	p.conn = conn
+	p.channel = channel
+	return nil
+}
+
+func (p *amqpPublisher) closeConnection() error {
+	if p.conn == nil || p.conn.IsClosed() {
+		return nil
+	}
+	return p.conn.Close()
+}
+
+func causationHeaders(causationID string) amqp.Table {
+	if causationID == "" {
+		return nil
+	}
+	return amqp.Table{causationHeader: causationID}
+}
@@ -0,0 +1,74 @@
+package publisher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// NewFilePublisher appends messages as JSON lines to the file at path, empty path means stdout
+func NewFilePublisher(path string) (Publisher, error) {
+	if path == "" {
+		return &filePublisher{w: os.Stdout}, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &filePublisher{w: f, file: f}, nil
+}
+
+type filePublisher struct {
+	mu   sync.Mutex
+	w    io.Writer
+	file *os.File
+}
+
+type fileMessage struct {
+	MessageID     string          `json:"message_id"`
+	CorrelationID string          `json:"correlation_id"`
+	CausationID   string          `json:"causation_id,omitempty"`
+	Type          string          `json:"type"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+func (p *filePublisher) Publish(_ context.Context, messages []Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	w := bufio.NewWriter(p.w)
+	encoder := json.NewEncoder(w)
+	for _, message := range messages {
+		err := encoder.Encode(fileMessage{
+			MessageID:     message.MessageID,
+			CorrelationID: message.CorrelationID,
+			CausationID:   message.CausationID,
+			Type:          message.Type,
+			Payload:       json.RawMessage(message.Payload),
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if p.file != nil {
+		return errors.WithStack(p.file.Sync())
+	}
+	return nil
+}
+
+func (p *filePublisher) Close() error {
+	if p.file != nil {
+		return p.file.Close()
+	}
+	return nil
+}
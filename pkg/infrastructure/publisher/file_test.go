@@ -0,0 +1,61 @@
+package publisher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	publisher, err := NewFilePublisher(path)
+	require.NoError(t, err)
+
+	err = publisher.Publish(context.Background(), []Message{
+		{
+			MessageID:     "message-1",
+			CorrelationID: "correlation",
+			CausationID:   "request",
+			Type:          "OrderCreated",
+			Payload:       `{"order_id":"1"}`,
+		},
+		{
+			MessageID:     "message-2",
+			CorrelationID: "correlation",
+			Type:          "OrderStatusChanged",
+			Payload:       `{"order_id":"1","status":2}`,
+		},
+	})
+	require.NoError(t, err)
+	err = publisher.Publish(context.Background(), []Message{
+		{MessageID: "message-3", CorrelationID: "message-3", Type: "OrderDeleted", Payload: `{}`},
+	})
+	require.NoError(t, err)
+	require.NoError(t, publisher.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Equal(t, []string{
+		`{"message_id":"message-1","correlation_id":"correlation","causation_id":"request","type":"OrderCreated","payload":{"order_id":"1"}}`,
+		`{"message_id":"message-2","correlation_id":"correlation","type":"OrderStatusChanged","payload":{"order_id":"1","status":2}}`,
+		`{"message_id":"message-3","correlation_id":"message-3","type":"OrderDeleted","payload":{}}`,
+	}, lines)
+
+	t.Run("Invalid payload is not written", func(t *testing.T) {
+		publisher, err := NewFilePublisher(path)
+		require.NoError(t, err)
+		defer publisher.Close()
+
+		err = publisher.Publish(context.Background(), []Message{{MessageID: "message-4", Payload: "not json"}})
+		require.Error(t, err)
+
+		after, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, data, after)
+	})
+}
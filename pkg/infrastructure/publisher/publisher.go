@@ -0,0 +1,64 @@
+package publisher
+
+import (
+	"context"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/outbox"
+
+	"order/pkg/infrastructure/integrationevent"
+)
+
+type Message struct {
+	// MessageID is unique per event
+	MessageID     string
+	CorrelationID string
+	CausationID   string
+	Type          string
+	Payload       string
+}
+
+// Publisher delivers messages to a sink, Publish returns only when all messages are accepted by it
+type Publisher interface {
+	Publish(ctx context.Context, messages []Message) error
+	Close() error
+}
+
+// NewOutboxTransport relays events read from the outbox to publisher
+func NewOutboxTransport(publisher Publisher) outbox.Transport {
+	return &outboxTransport{
+		publisher: publisher,
+	}
+}
+
+type outboxTransport struct {
+	publisher Publisher
+}
+
+func (t outboxTransport) HandleEvents(ctx context.Context, events []outbox.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	messages := make([]Message, 0, len(events))
+	for _, event := range events {
+		envelope, ok := integrationevent.UnmarshalEnvelope(event.Payload)
+		if !ok {
+			// event stored before envelopes has only the id generated by golib
+			messages = append(messages, Message{
+				MessageID:     event.CorrelationID,
+				CorrelationID: event.CorrelationID,
+				Type:          event.EventType,
+				Payload:       event.Payload,
+			})
+			continue
+		}
+		messages = append(messages, Message{
+			MessageID:     envelope.MessageID,
+			CorrelationID: envelope.CorrelationID,
+			CausationID:   envelope.CausationID,
+			Type:          event.EventType,
+			Payload:       string(envelope.Payload),
+		})
+	}
+	return t.publisher.Publish(ctx, messages)
+}
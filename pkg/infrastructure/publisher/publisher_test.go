@@ -0,0 +1,75 @@
+package publisher
+
+import (
+	"context"
+	"testing"
+
+	"gitea.xscloud.ru/xscloud/golib/pkg/infrastructure/outbox"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"order/pkg/infrastructure/integrationevent"
+)
+
+func TestOutboxTransport(t *testing.T) {
+	payload, err := integrationevent.Envelope{
+		MessageID:     "message",
+		CorrelationID: "correlation",
+		CausationID:   "request",
+		Payload:       []byte(`{"order_id":"1"}`),
+	}.Marshal()
+	require.NoError(t, err)
+	events := []outbox.Event{
+		{CorrelationID: "message", EventType: "OrderCreated", Payload: payload},
+		{CorrelationID: "legacy", EventType: "OrderDeleted", Payload: `{"order_id":"2"}`},
+	}
+
+	t.Run("Envelope is unwrapped", func(t *testing.T) {
+		publisher := &mockPublisher{}
+		require.NoError(t, NewOutboxTransport(publisher).HandleEvents(context.Background(), events))
+		require.Equal(t, [][]Message{{
+			{
+				MessageID:     "message",
+				CorrelationID: "correlation",
+				CausationID:   "request",
+				Type:          "OrderCreated",
+				Payload:       `{"order_id":"1"}`,
+			},
+			{
+				MessageID:     "legacy",
+				CorrelationID: "legacy",
+				Type:          "OrderDeleted",
+				Payload:       `{"order_id":"2"}`,
+			},
+		}}, publisher.batches)
+	})
+
+	// outbox handler tracks the batch as handled only when transport returns no error
+	t.Run("Batch is not acknowledged when publishing fails", func(t *testing.T) {
+		publishErr := errors.New("broker is unavailable")
+		publisher := &mockPublisher{err: publishErr}
+		err := NewOutboxTransport(publisher).HandleEvents(context.Background(), events)
+		require.ErrorIs(t, err, publishErr)
+		require.Len(t, publisher.batches, 1)
+	})
+
+	t.Run("Empty batch is not published", func(t *testing.T) {
+		publisher := &mockPublisher{}
+		require.NoError(t, NewOutboxTransport(publisher).HandleEvents(context.Background(), nil))
+		require.Empty(t, publisher.batches)
+	})
+}
+
+type mockPublisher struct {
+	batches [][]Message
+	err     error
+}
+
+func (m *mockPublisher) Publish(_ context.Context, messages []Message) error {
+	m.batches = append(m.batches, messages)
+	return m.err
+}
+
+func (m *mockPublisher) Close() error {
+	return nil
+}
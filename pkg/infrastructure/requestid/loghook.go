@@ -0,0 +1,34 @@
+package requestid
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	logField            = "request_id"
+	correlationLogField = "correlation_id"
+)
+
+// NewLogHook adds request and correlation ids to entries logged with a context carrying it, see log.Entry.WithContext
+func NewLogHook() log.Hook {
+	return logHook{}
+}
+
+type logHook struct{}
+
+func (logHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (logHook) Fire(entry *log.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	if requestID := FromContext(entry.Context); requestID != "" {
+		entry.Data[logField] = requestID
+	}
+	if correlationID := CorrelationIDFromContext(entry.Context); correlationID != "" {
+		entry.Data[correlationLogField] = correlationID
+	}
+	return nil
+}
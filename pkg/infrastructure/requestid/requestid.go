@@ -0,0 +1,60 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// gRPC metadata keys carrying ids both for incoming and outgoing calls
+const (
+	Header = "x-request-id"
+	// CorrelationHeader ties together requests of one flow across services, it defaults to the request id
+	CorrelationHeader = "x-correlation-id"
+)
+
+const maxLength = 128
+
+type (
+	requestIDKey     struct{}
+	correlationIDKey struct{}
+)
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// FromContext returns request id stored in ctx or an empty string
+func FromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// CorrelationIDFromContext returns correlation id stored in ctx, request id when there is none or an empty string
+func CorrelationIDFromContext(ctx context.Context) string {
+	if correlationID, _ := ctx.Value(correlationIDKey{}).(string); correlationID != "" {
+		return correlationID
+	}
+	return FromContext(ctx)
+}
+
+func New() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// IsValid accepts non-empty printable ASCII ids of bounded length
+func IsValid(requestID string) bool {
+	if requestID == "" || len(requestID) > maxLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValid(t *testing.T) {
+	require.True(t, IsValid(New()))
+	require.True(t, IsValid("req-42"))
+	require.False(t, IsValid(""))
+	require.False(t, IsValid("with space"))
+	require.False(t, IsValid("line\nbreak"))
+	require.False(t, IsValid(strings.Repeat("a", maxLength+1)))
+}
+
+func TestLogHook(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	logger.AddHook(NewLogHook())
+
+	logger.WithContext(WithRequestID(context.Background(), "req-42")).Info("with request")
+	require.Equal(t, "req-42", hook.LastEntry().Data[logField])
+	require.Equal(t, "req-42", hook.LastEntry().Data[correlationLogField])
+
+	ctx := WithCorrelationID(WithRequestID(context.Background(), "req-42"), "flow-1")
+	logger.WithContext(ctx).Info("with correlation")
+	require.Equal(t, "req-42", hook.LastEntry().Data[logField])
+	require.Equal(t, "flow-1", hook.LastEntry().Data[correlationLogField])
+
+	logger.WithContext(context.Background()).Info("without request")
+	require.NotContains(t, hook.LastEntry().Data, logField)
+
+	logger.Info("without context")
+	require.NotContains(t, hook.LastEntry().Data, logField)
+}
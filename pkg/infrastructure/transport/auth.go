@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeader = "authorization"
+	bearerPrefix        = "Bearer "
+)
+
+// NewTokenAuthInterceptor accepts calls with "authorization: Bearer <token>" metadata where token is one of tokens.
+// Without tokens every call is rejected, so the service refuses to start with auth interceptor and no tokens
+func NewTokenAuthInterceptor(tokens []string) *AuthInterceptor {
+	return &AuthInterceptor{tokens: tokens}
+}
+
+type AuthInterceptor struct {
+	tokens []string
+}
+
+func (i *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := i.authenticate(ctx); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (i *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := i.authenticate(stream.Context()); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+func (i *AuthInterceptor) authenticate(ctx context.Context) error {
+	values := metadata.ValueFromIncomingContext(ctx, authorizationHeader)
+	if len(values) == 0 || !strings.HasPrefix(values[0], bearerPrefix) {
+		return status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	token := []byte(strings.TrimPrefix(values[0], bearerPrefix))
+	for _, known := range i.tokens {
+		if subtle.ConstantTimeCompare(token, []byte(known)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "invalid bearer token")
+}
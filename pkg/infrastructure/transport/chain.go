@@ -0,0 +1,74 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+)
+
+var (
+	ErrUnknownInterceptor   = errors.New("unknown interceptor")
+	ErrDuplicateInterceptor = errors.New("interceptor is listed more than once")
+)
+
+// Interceptor is a single step of the server chain, Unary or Stream is nil when the step does not apply to such calls
+type Interceptor struct {
+	Unary  grpc.UnaryServerInterceptor
+	Stream grpc.StreamServerInterceptor
+}
+
+// NewChainBuilder creates an empty builder, interceptors are registered by name and applied in the order passed to Build
+func NewChainBuilder() *ChainBuilder {
+	return &ChainBuilder{
+		interceptors: make(map[string]Interceptor),
+	}
+}
+
+type ChainBuilder struct {
+	interceptors map[string]Interceptor
+}
+
+func (b *ChainBuilder) Register(name string, interceptor Interceptor) *ChainBuilder {
+	b.interceptors[name] = interceptor
+	return b
+}
+
+// Build chains interceptors listed in order for both unary and stream calls, the first one is the outermost.
+// Registered interceptors missing from order are not applied
+func (b *ChainBuilder) Build(order []string) ([]grpc.ServerOption, error) {
+	unary, stream, err := b.collect(order)
+	if err != nil {
+		return nil, err
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}, nil
+}
+
+func (b *ChainBuilder) collect(order []string) ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor, error) {
+	var (
+		unary  []grpc.UnaryServerInterceptor
+		stream []grpc.StreamServerInterceptor
+		seen   = make(map[string]struct{}, len(order))
+	)
+	for _, name := range order {
+		interceptor, ok := b.interceptors[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %q", ErrUnknownInterceptor, name)
+		}
+		if _, ok = seen[name]; ok {
+			return nil, nil, fmt.Errorf("%w: %q", ErrDuplicateInterceptor, name)
+		}
+		seen[name] = struct{}{}
+
+		if interceptor.Unary != nil {
+			unary = append(unary, interceptor.Unary)
+		}
+		if interceptor.Stream != nil {
+			stream = append(stream, interceptor.Stream)
+		}
+	}
+	return unary, stream, nil
+}
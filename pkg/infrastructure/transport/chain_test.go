@@ -0,0 +1,125 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"order/pkg/domain/model"
+)
+
+func TestChainBuilder(t *testing.T) {
+	var calls []string
+	recording := func(name string) Interceptor {
+		return Interceptor{
+			Unary: func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				calls = append(calls, name)
+				return handler(ctx, req)
+			},
+			Stream: func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				calls = append(calls, name)
+				return handler(srv, stream)
+			},
+		}
+	}
+	builder := NewChainBuilder().
+		Register("first", recording("first")).
+		Register("second", recording("second")).
+		Register("unaryOnly", Interceptor{Unary: recording("unaryOnly").Unary}).
+		Register("disabled", recording("disabled"))
+
+	t.Run("Applies in configured order", func(t *testing.T) {
+		unary, stream, err := builder.collect([]string{"second", "unaryOnly", "first"})
+		require.NoError(t, err)
+		require.Len(t, unary, 3)
+		require.Len(t, stream, 2)
+
+		calls = nil
+		for _, interceptor := range unary {
+			_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{},
+				func(context.Context, interface{}) (interface{}, error) { return nil, nil },
+			)
+			require.NoError(t, err)
+		}
+		for _, interceptor := range stream {
+			err = interceptor(nil, nil, &grpc.StreamServerInfo{},
+				func(interface{}, grpc.ServerStream) error { return nil },
+			)
+			require.NoError(t, err)
+		}
+		require.Equal(t, []string{"second", "unaryOnly", "first", "second", "first"}, calls)
+	})
+
+	t.Run("Unknown interceptor", func(t *testing.T) {
+		_, err := builder.Build([]string{"first", "tracing"})
+		require.ErrorIs(t, err, ErrUnknownInterceptor)
+	})
+
+	t.Run("Duplicate interceptor", func(t *testing.T) {
+		_, err := builder.Build([]string{"first", "first"})
+		require.ErrorIs(t, err, ErrDuplicateInterceptor)
+	})
+}
+
+func TestErrorInterceptorStream(t *testing.T) {
+	err := ErrorInterceptor{}.Stream()(nil, nil, &grpc.StreamServerInfo{},
+		func(interface{}, grpc.ServerStream) error { return model.ErrOrderNotFound },
+	)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	handler := func(context.Context, interface{}) (interface{}, error) { return "ok", nil }
+	withToken := func(value string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationHeader, value))
+	}
+
+	t.Run("Rejected without tokens", func(t *testing.T) {
+		_, err := NewTokenAuthInterceptor(nil).Unary()(withToken("Bearer "), nil, &grpc.UnaryServerInfo{}, handler)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	interceptor := NewTokenAuthInterceptor([]string{"secret"}).Unary()
+	for name, ctx := range map[string]context.Context{
+		"Missing token": context.Background(),
+		"Invalid token": withToken("Bearer guess"),
+		"Wrong scheme":  withToken("Basic secret"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			require.Equal(t, codes.Unauthenticated, status.Code(err))
+		})
+	}
+
+	t.Run("Valid token", func(t *testing.T) {
+		resp, err := interceptor(withToken("Bearer secret"), nil, &grpc.UnaryServerInfo{}, handler)
+		require.NoError(t, err)
+		require.Equal(t, "ok", resp)
+	})
+}
+
+func TestMetricsInterceptor(t *testing.T) {
+	interceptor := NewMetricsInterceptor(prometheus.NewRegistry())
+	const route = "/Order.OrderService/GetOrder"
+
+	_, _ = interceptor.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: route},
+		func(context.Context, interface{}) (interface{}, error) { return nil, model.ErrOrderNotFound },
+	)
+	_ = interceptor.Stream()(nil, nil, &grpc.StreamServerInfo{FullMethod: route},
+		func(interface{}, grpc.ServerStream) error { return status.Error(codes.NotFound, "not found") },
+	)
+	_ = interceptor.Stream()(nil, nil, &grpc.StreamServerInfo{FullMethod: route},
+		func(interface{}, grpc.ServerStream) error { return errors.New("boom") },
+	)
+
+	require.InDelta(t, 2, testutil.ToFloat64(interceptor.requests.WithLabelValues(route, codes.NotFound.String())), 0)
+	require.InDelta(t, 1, testutil.ToFloat64(interceptor.requests.WithLabelValues(route, codes.Unknown.String())), 0)
+}
@@ -2,59 +2,41 @@ package transport
 
 import (
 	"context"
+	"errors"
 
-	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
-)
-
-type errorSet map[error]struct{}
+	"google.golang.org/grpc/status"
 
-func newErrorSet(errs ...error) errorSet {
-	s := make(errorSet)
-	for _, err := range errs {
-		s[err] = struct{}{}
-	}
-	return s
-}
+	"order/pkg/domain/model"
+)
 
-func (s errorSet) Has(err error) bool {
-	_, ok := s[err]
-	return ok
+// errorCategoryCodes maps categories declared with model.NewError to GRPC codes
+var errorCategoryCodes = map[model.ErrorCategory]codes.Code{
+	model.InvalidArgumentError:    codes.InvalidArgument,
+	model.NotFoundError:           codes.NotFound,
+	model.FailedPreconditionError: codes.FailedPrecondition,
+	model.ConflictError:           codes.AlreadyExists,
+	model.AbortedError:            codes.Aborted,
+	model.UnavailableError:        codes.Unavailable,
 }
 
-var badRequestErrorCodes = newErrorSet()
-
-var notFoundErrorCodes = newErrorSet()
-
-var unauthorizedErrorCodes = newErrorSet()
-
-var permissionDeniedErrorCodes = newErrorSet()
-
-var internalErrorCodes = newErrorSet()
-
-// getGRPCCode recursively unwraps joined errors and returns GRPC code by the first meaningful error
+// getGRPCCode recursively unwraps wrapped and joined errors and returns GRPC code by the first meaningful error
 func getGRPCCode(err error) codes.Code {
-	cause := errors.Cause(err)
-
-	switch {
-	case cause == nil:
+	if err == nil {
 		return codes.OK
-	case isBadRequestError(cause):
-		return codes.InvalidArgument
-	case isNotFoundError(cause):
-		return codes.NotFound
-	case isUnauthorizedError(cause):
-		return codes.Unauthenticated
-	case isPermissionDeniedError(cause):
-		return codes.PermissionDenied
-	case isInternalError(cause):
-		return codes.Internal
+	}
+	// already translated errors keep their code
+	if st, ok := status.FromError(err); ok {
+		return st.Code()
+	}
+	if code, ok := errorCategoryCodes[model.CategoryOf(err)]; ok {
+		return code
 	}
 
-	switch cause {
-	case context.DeadlineExceeded:
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
 		return codes.DeadlineExceeded
-	case context.Canceled:
+	case errors.Is(err, context.Canceled):
 		return codes.Canceled
 	default:
 		return codes.Unknown
@@ -69,29 +51,11 @@ func isWarnLevel(err error) bool {
 		codes.InvalidArgument,
 		codes.NotFound,
 		codes.FailedPrecondition,
+		codes.AlreadyExists,
+		codes.Aborted,
 		codes.Unauthenticated:
 		return true
 	default:
 		return false
 	}
 }
-
-func isBadRequestError(cause error) bool {
-	return badRequestErrorCodes.Has(cause)
-}
-
-func isNotFoundError(cause error) bool {
-	return notFoundErrorCodes.Has(cause)
-}
-
-func isUnauthorizedError(cause error) bool {
-	return unauthorizedErrorCodes.Has(cause)
-}
-
-func isPermissionDeniedError(cause error) bool {
-	return permissionDeniedErrorCodes.Has(cause)
-}
-
-func isInternalError(cause error) bool {
-	return internalErrorCodes.Has(cause)
-}
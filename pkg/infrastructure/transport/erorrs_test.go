@@ -0,0 +1,47 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+	domainservice "order/pkg/domain/service"
+)
+
+func TestGetGRPCCode(t *testing.T) {
+	for name, testCase := range map[string]struct {
+		err  error
+		code codes.Code
+	}{
+		"nil":                   {nil, codes.OK},
+		"not found":             {model.ErrOrderNotFound, codes.NotFound},
+		"failed precondition":   {domainservice.ErrInvalidOrderStatus, codes.FailedPrecondition},
+		"invalid argument":      {domainservice.ErrInvalidQuantity, codes.InvalidArgument},
+		"conflict":              {service.ErrIdempotencyKeyReused, codes.AlreadyExists},
+		"aborted":               {model.ErrConcurrentModification, codes.Aborted},
+		"unavailable":           {model.ErrProductUnavailable, codes.Unavailable},
+		"typed error":           {domainservice.StatusTransitionError{From: model.Paid, To: model.Open}, codes.FailedPrecondition},
+		"wrapped with fmt":      {fmt.Errorf("add item: %w", model.ErrItemNotFound), codes.NotFound},
+		"wrapped with pkg":      {pkgerrors.Wrap(model.ErrItemNotFound, "add item"), codes.NotFound},
+		"joined":                {errors.Join(errors.New("rollback failed"), model.ErrOrderNotFound), codes.NotFound},
+		"joined first category": {errors.Join(model.ErrConcurrentModification, model.ErrOrderNotFound), codes.Aborted},
+		"joined and wrapped":    {fmt.Errorf("execute: %w", errors.Join(fmt.Errorf("find: %w", model.ErrOrderNotFound))), codes.NotFound},
+		"deadline":              {fmt.Errorf("query: %w", context.DeadlineExceeded), codes.DeadlineExceeded},
+		"canceled":              {context.Canceled, codes.Canceled},
+		"unknown":               {errors.New("unknown"), codes.Unknown},
+		"status":                {status.Error(codes.Unauthenticated, "missing bearer token"), codes.Unauthenticated},
+		"status over category":  {status.Error(codes.InvalidArgument, model.ErrOrderNotFound.Error()), codes.InvalidArgument},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, testCase.code, getGRPCCode(testCase.err))
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"order/pkg/domain/model"
+	domainservice "order/pkg/domain/service"
+)
+
+// errorDomain is google.rpc.ErrorInfo domain, reasons are unique within it
+const errorDomain = "order"
+
+const invalidFieldReason = "INVALID_FIELD"
+
+// newErrorStatus builds status with google.rpc.ErrorInfo, BadRequest and PreconditionFailure details where they apply.
+// Identifiers from the request, such as order_id, are added to ErrorInfo metadata
+func newErrorStatus(req interface{}, err error) *status.Status {
+	st := status.New(getGRPCCode(err), err.Error())
+	ids := requestIDs(req)
+
+	var details []protoadapt.MessageV1
+	if reason := model.ReasonOf(err); reason != "" {
+		details = append(details, &errdetails.ErrorInfo{
+			Reason:   reason,
+			Domain:   errorDomain,
+			Metadata: ids,
+		})
+	}
+	if violations := fieldViolations(err); len(violations) > 0 {
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	if violations := preconditionViolations(err, ids["order_id"]); len(violations) > 0 {
+		details = append(details, &errdetails.PreconditionFailure{Violations: violations})
+	}
+	if len(details) == 0 {
+		return st
+	}
+
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st
+	}
+	return withDetails
+}
+
+// invalidFieldError is returned by handlers for malformed request fields and headers
+func invalidFieldError(field, description string) error {
+	st := status.New(codes.InvalidArgument, fmt.Sprintf("invalid %s: %s", field, description))
+	withDetails, err := st.WithDetails(
+		&errdetails.ErrorInfo{
+			Reason:   invalidFieldReason,
+			Domain:   errorDomain,
+			Metadata: map[string]string{"field": field},
+		},
+		&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: field, Description: description},
+			},
+		},
+	)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+func fieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	fieldErrors := model.FieldErrorsOf(err)
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fieldErrors))
+	for _, fieldErr := range fieldErrors {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       fieldErr.Field,
+			Description: fieldErr.Err.Error(),
+		})
+	}
+	return violations
+}
+
+func preconditionViolations(err error, orderID string) []*errdetails.PreconditionFailure_Violation {
+	subject := ""
+	if orderID != "" {
+		subject = "orders/" + orderID
+	}
+
+	var transitionErr domainservice.StatusTransitionError
+	if errors.As(err, &transitionErr) {
+		return []*errdetails.PreconditionFailure_Violation{{
+			Type:        "STATUS_TRANSITION",
+			Subject:     subject,
+			Description: fmt.Sprintf("order status can not change from %v to %v", transitionErr.From, transitionErr.To),
+		}}
+	}
+
+	var priceErr domainservice.PriceChangedError
+	if errors.As(err, &priceErr) {
+		violations := make([]*errdetails.PreconditionFailure_Violation, 0, len(priceErr.Changes))
+		for _, change := range priceErr.Changes {
+			violations = append(violations, &errdetails.PreconditionFailure_Violation{
+				Type:    "PRICE_CHANGED",
+				Subject: "items/" + change.ItemID.String(),
+				Description: fmt.Sprintf(
+					"price changed from %d %s to %d %s",
+					change.OldPrice.Amount(), change.OldPrice.Currency(),
+					change.NewPrice.Amount(), change.NewPrice.Currency(),
+				),
+			})
+		}
+		return violations
+	}
+
+	if errors.Is(err, domainservice.ErrInvalidOrderStatus) || errors.Is(err, domainservice.ErrPaidOrderDeletion) {
+		return []*errdetails.PreconditionFailure_Violation{{
+			Type:        "ORDER_STATUS",
+			Subject:     subject,
+			Description: err.Error(),
+		}}
+	}
+	return nil
+}
+
+// requestIDs collects non-empty string fields with _id suffix of the request message
+func requestIDs(req interface{}) map[string]string {
+	message, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	ids := map[string]string{}
+	message.ProtoReflect().Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		name := string(field.Name())
+		if field.Kind() == protoreflect.StringKind && !field.IsList() && strings.HasSuffix(name, "_id") {
+			ids[name] = value.String()
+		}
+		return true
+	})
+	return ids
+}
@@ -0,0 +1,156 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "order/api/server/order"
+	"order/pkg/domain/model"
+	domainservice "order/pkg/domain/service"
+)
+
+func TestErrorDetails(t *testing.T) {
+	orderID := uuid.Must(uuid.NewV7()).String()
+
+	t.Run("Status transition", func(t *testing.T) {
+		err := fmt.Errorf("set status: %w", domainservice.StatusTransitionError{From: model.Paid, To: model.Open})
+		st := newErrorStatus(&api.SetStatusRequest{OrderId: orderID}, err)
+
+		require.Equal(t, codes.FailedPrecondition, st.Code())
+		require.Equal(t, []interface{}{
+			&errdetails.ErrorInfo{
+				Reason:   "INVALID_STATUS_TRANSITION",
+				Domain:   errorDomain,
+				Metadata: map[string]string{"order_id": orderID},
+			},
+			&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{
+				Type:        "STATUS_TRANSITION",
+				Subject:     "orders/" + orderID,
+				Description: "order status can not change from Paid to Open",
+			}}},
+		}, protoDetails(st))
+	})
+
+	t.Run("Validation error", func(t *testing.T) {
+		itemID := uuid.Must(uuid.NewV7()).String()
+		st := newErrorStatus(&api.ChangeQuantityRequest{OrderId: orderID, ItemId: itemID}, domainservice.ErrInvalidQuantity)
+
+		require.Equal(t, codes.InvalidArgument, st.Code())
+		require.Equal(t, []interface{}{
+			&errdetails.ErrorInfo{
+				Reason:   "INVALID_QUANTITY",
+				Domain:   errorDomain,
+				Metadata: map[string]string{"order_id": orderID, "item_id": itemID},
+			},
+			&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       "quantity",
+				Description: domainservice.ErrInvalidQuantity.Error(),
+			}}},
+		}, protoDetails(st))
+	})
+
+	t.Run("Joined validation errors", func(t *testing.T) {
+		err := errors.Join(
+			fmt.Errorf("reason: %w", domainservice.ErrInvalidCancellationReason),
+			domainservice.ErrEmptyActor,
+			domainservice.ErrInvalidCancellationReason,
+		)
+		for range 10 {
+			st := newErrorStatus(&api.CancelOrderRequest{OrderId: orderID}, err)
+
+			require.Equal(t, codes.InvalidArgument, st.Code())
+			require.Equal(t, &errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "reason", Description: domainservice.ErrInvalidCancellationReason.Error()},
+				{Field: "actor", Description: domainservice.ErrEmptyActor.Error()},
+			}}, protoDetails(st)[1])
+		}
+	})
+
+	t.Run("Price changed", func(t *testing.T) {
+		itemID := uuid.Must(uuid.NewV7())
+		oldPrice, err := model.NewMoney(1000, "RUB")
+		require.NoError(t, err)
+		newPrice, err := model.NewMoney(1200, "RUB")
+		require.NoError(t, err)
+		priceErr := domainservice.PriceChangedError{Changes: []model.ItemPriceChange{{
+			ItemID:    itemID,
+			ProductID: uuid.Must(uuid.NewV7()),
+			OldPrice:  oldPrice,
+			NewPrice:  newPrice,
+		}}}
+		st := newErrorStatus(&api.CheckoutRequest{OrderId: orderID}, fmt.Errorf("checkout: %w", priceErr))
+
+		require.Equal(t, codes.FailedPrecondition, st.Code())
+		require.Equal(t, []interface{}{
+			&errdetails.ErrorInfo{
+				Reason:   "PRICE_CHANGED",
+				Domain:   errorDomain,
+				Metadata: map[string]string{"order_id": orderID},
+			},
+			&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{
+				Type:        "PRICE_CHANGED",
+				Subject:     "items/" + itemID.String(),
+				Description: "price changed from 1000 RUB to 1200 RUB",
+			}}},
+		}, protoDetails(st))
+	})
+
+	t.Run("Paid order deletion", func(t *testing.T) {
+		st := newErrorStatus(&api.DeleteOrderRequest{OrderId: orderID}, domainservice.ErrPaidOrderDeletion)
+
+		require.Equal(t, codes.FailedPrecondition, st.Code())
+		require.Equal(t, []interface{}{
+			&errdetails.ErrorInfo{
+				Reason:   "PAID_ORDER_DELETION",
+				Domain:   errorDomain,
+				Metadata: map[string]string{"order_id": orderID},
+			},
+			&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{
+				Type:        "ORDER_STATUS",
+				Subject:     "orders/" + orderID,
+				Description: "paid order can not be deleted",
+			}}},
+		}, protoDetails(st))
+	})
+
+	t.Run("Uncategorized error has no details", func(t *testing.T) {
+		st := newErrorStatus(&api.GetOrderRequest{OrderId: orderID}, fmt.Errorf("connection refused"))
+		require.Equal(t, codes.Unknown, st.Code())
+		require.Empty(t, st.Details())
+	})
+
+	t.Run("Invalid field", func(t *testing.T) {
+		_, err := parseID("order_id", "not uuid")
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+
+		details := protoDetails(st)
+		require.Len(t, details, 2)
+		require.Equal(t, invalidFieldReason, details[0].(*errdetails.ErrorInfo).Reason)
+		require.Equal(t, "order_id", details[1].(*errdetails.BadRequest).FieldViolations[0].Field)
+	})
+}
+
+// protoDetails drops internal state of decoded messages, so that they can be compared with literals
+func protoDetails(st *status.Status) []interface{} {
+	details := st.Details()
+	for i, detail := range details {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			details[i] = &errdetails.ErrorInfo{Reason: d.Reason, Domain: d.Domain, Metadata: d.Metadata}
+		case *errdetails.BadRequest:
+			details[i] = &errdetails.BadRequest{FieldViolations: d.FieldViolations}
+		case *errdetails.PreconditionFailure:
+			details[i] = &errdetails.PreconditionFailure{Violations: d.Violations}
+		}
+	}
+	return details
+}
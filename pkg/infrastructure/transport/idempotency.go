@@ -0,0 +1,107 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"order/pkg/application/service"
+)
+
+const (
+	idempotencyKeyHeader    = "idempotency-key"
+	maxIdempotencyKeyLength = 255
+)
+
+// MakeIdempotencyServerInterceptor makes methods replay the stored response for a repeated idempotency-key header.
+// Requests without the header are executed as usual
+func MakeIdempotencyServerInterceptor(idempotencyService service.IdempotencyService, methods []string) grpc.UnaryServerInterceptor {
+	idempotentMethods := make(map[string]struct{}, len(methods))
+	for _, method := range methods {
+		idempotentMethods[method] = struct{}{}
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := idempotentMethods[info.FullMethod]; !ok {
+			return handler(ctx, req)
+		}
+		keys := metadata.ValueFromIncomingContext(ctx, idempotencyKeyHeader)
+		if len(keys) == 0 {
+			return handler(ctx, req)
+		}
+		key := keys[0]
+		if key == "" || len(key) > maxIdempotencyKeyLength {
+			return nil, invalidFieldError(idempotencyKeyHeader, fmt.Sprintf("must be from 1 to %d characters", maxIdempotencyKeyLength))
+		}
+
+		requestHash, err := hashRequest(info.FullMethod, req)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp interface{}
+		data, err := idempotencyService.Execute(ctx, key, requestHash, func(ctx context.Context) ([]byte, error) {
+			var err2 error
+			resp, err2 = handler(ctx, req)
+			if err2 != nil {
+				return nil, err2
+			}
+			return proto.Marshal(resp.(proto.Message))
+		})
+		if err != nil {
+			return nil, err
+		}
+		if resp != nil {
+			return resp, nil
+		}
+		return unmarshalResponse(info.FullMethod, data)
+	}
+}
+
+// hashRequest includes method, so the same key can not be reused for another method
+func hashRequest(method string, req interface{}) ([]byte, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req.(proto.Message))
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write(data)
+	return hash.Sum(nil), nil
+}
+
+func unmarshalResponse(fullMethod string, data []byte) (proto.Message, error) {
+	serviceName, methodName, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "invalid method %s", fullMethod)
+	}
+	descriptor, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, err
+	}
+	serviceDescriptor, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "%s is not a service", serviceName)
+	}
+	methodDescriptor := serviceDescriptor.Methods().ByName(protoreflect.Name(methodName))
+	if methodDescriptor == nil {
+		return nil, status.Errorf(codes.Internal, "unknown method %s", fullMethod)
+	}
+	messageType, err := protoregistry.GlobalTypes.FindMessageByName(methodDescriptor.Output().FullName())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := messageType.New().Interface()
+	return resp, proto.Unmarshal(data, resp)
+}
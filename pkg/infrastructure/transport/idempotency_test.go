@@ -0,0 +1,131 @@
+package transport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	api "order/api/server/order"
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+)
+
+func TestIdempotencyInterceptor(t *testing.T) {
+	repo := &memoryIdempotencyRepository{records: map[string]service.IdempotencyRecord{}}
+	uow := &idempotencyUnitOfWork{repo: repo}
+	idempotencyService := service.NewIdempotencyService(uow, idempotencyLockableUnitOfWork{uow}, service.IdempotencyOptions{
+		TTL:            time.Hour,
+		PendingTimeout: time.Minute,
+	})
+	interceptor := MakeIdempotencyServerInterceptor(idempotencyService, []string{api.OrderService_CreateOrder_FullMethodName})
+	info := &grpc.UnaryServerInfo{FullMethod: api.OrderService_CreateOrder_FullMethodName}
+
+	calls := 0
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		calls++
+		return &api.CreateOrderResponse{OrderId: "order"}, nil
+	}
+	withKey := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(idempotencyKeyHeader, key))
+	}
+	request := &api.CreateOrderRequest{CustomerId: "customer"}
+
+	t.Run("Repeated request replays stored response", func(t *testing.T) {
+		resp, err := interceptor(withKey("key"), request, info, handler)
+		require.NoError(t, err)
+		require.Equal(t, "order", resp.(*api.CreateOrderResponse).OrderId)
+
+		resp, err = interceptor(withKey("key"), proto.Clone(request), info, handler)
+		require.NoError(t, err)
+		require.Equal(t, "order", resp.(*api.CreateOrderResponse).OrderId)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("Key reused for another request", func(t *testing.T) {
+		_, err := interceptor(withKey("key"), &api.CreateOrderRequest{CustomerId: "another"}, info, handler)
+		require.Equal(t, codes.AlreadyExists, getGRPCCode(err))
+		require.ErrorIs(t, err, service.ErrIdempotencyKeyReused)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("Request in progress", func(t *testing.T) {
+		_, err := interceptor(withKey("pending"), request, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			_, err := interceptor(withKey("pending"), request, info, handler)
+			require.ErrorIs(t, err, service.ErrIdempotentRequestInProgress)
+			return handler(ctx, req)
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("Invalid key", func(t *testing.T) {
+		_, err := interceptor(withKey(""), request, info, handler)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("Request without key is not stored", func(t *testing.T) {
+		_, err := interceptor(context.Background(), request, info, handler)
+		require.NoError(t, err)
+		_, err = interceptor(context.Background(), request, info, handler)
+		require.NoError(t, err)
+		require.Equal(t, 4, calls)
+	})
+}
+
+type idempotencyUnitOfWork struct {
+	repo *memoryIdempotencyRepository
+}
+
+func (u *idempotencyUnitOfWork) Execute(_ context.Context, f func(provider service.RepositoryProvider) error) error {
+	return f(u)
+}
+
+func (u *idempotencyUnitOfWork) OrderRepository(context.Context) model.OrderRepository {
+	panic("unexpected order repository")
+}
+
+func (u *idempotencyUnitOfWork) IdempotencyRepository(context.Context) service.IdempotencyRepository {
+	return u.repo
+}
+
+type idempotencyLockableUnitOfWork struct {
+	uow *idempotencyUnitOfWork
+}
+
+func (u idempotencyLockableUnitOfWork) Execute(ctx context.Context, _ string, f func(provider service.RepositoryProvider) error) error {
+	return u.uow.Execute(ctx, f)
+}
+
+type memoryIdempotencyRepository struct {
+	records map[string]service.IdempotencyRecord
+}
+
+func (r *memoryIdempotencyRepository) Find(key string) (*service.IdempotencyRecord, error) {
+	record, ok := r.records[key]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (r *memoryIdempotencyRepository) Store(record service.IdempotencyRecord) error {
+	r.records[record.Key] = record
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) Delete(key string) error {
+	delete(r.records, key)
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) DeleteExpired(time.Time) error {
+	return nil
+}
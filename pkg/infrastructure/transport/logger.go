@@ -13,7 +13,8 @@ type ErrorInterceptor struct {
 	Logger *log.Logger
 }
 
-func (i ErrorInterceptor) TranslateGRPCError(err error) error {
+// TranslateGRPCError converts err returned for req to a GRPC status with error details
+func (i ErrorInterceptor) TranslateGRPCError(req interface{}, err error) error {
 	if err == nil {
 		return nil
 	}
@@ -22,7 +23,21 @@ func (i ErrorInterceptor) TranslateGRPCError(err error) error {
 		return err
 	}
 
-	return status.Error(getGRPCCode(err), err.Error())
+	return newErrorStatus(req, err).Err()
+}
+
+func (i ErrorInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, i.TranslateGRPCError(req, err)
+	}
+}
+
+// Stream translates errors without request details since a stream has no single request
+func (i ErrorInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return i.TranslateGRPCError(nil, handler(srv, stream))
+	}
 }
 
 func MakeLoggerServerInterceptor(logger *log.Logger) grpc.UnaryServerInterceptor {
@@ -31,22 +46,37 @@ func MakeLoggerServerInterceptor(logger *log.Logger) grpc.UnaryServerInterceptor
 
 		resp, err = handler(ctx, req)
 
-		duration := time.Since(start).String()
-		fields := log.Fields{
-			"duration": duration,
-			"route":    info.FullMethod,
-		}
+		logCall(ctx, logger, info.FullMethod, start, err)
+		return resp, err
+	}
+}
 
-		loggerWithFields := logger.WithFields(fields)
-		if err == nil {
-			loggerWithFields.Infof("call finished")
+func MakeLoggerStreamServerInterceptor(logger *log.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		err := handler(srv, stream)
+
+		logCall(stream.Context(), logger, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logCall(ctx context.Context, logger *log.Logger, route string, start time.Time, err error) {
+	duration := time.Since(start).String()
+	fields := log.Fields{
+		"duration": duration,
+		"route":    route,
+	}
+
+	loggerWithFields := logger.WithContext(ctx).WithFields(fields)
+	if err == nil {
+		loggerWithFields.Infof("call finished")
+	} else {
+		if isWarnLevel(err) {
+			loggerWithFields.Warnf("call failed: %v", err)
 		} else {
-			if isWarnLevel(err) {
-				loggerWithFields.Warnf("call failed: %v", err)
-			} else {
-				loggerWithFields.Errorf("call failed: %v", err)
-			}
+			loggerWithFields.Errorf("call failed: %v", err)
 		}
-		return resp, err
 	}
 }
@@ -0,0 +1,56 @@
+package transport
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+)
+
+// NewMetricsInterceptor counts calls by route and GRPC code and observes their duration
+func NewMetricsInterceptor(registerer prometheus.Registerer) *MetricsInterceptor {
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "order_grpc_requests_total",
+		Help: "Handled gRPC calls by route and code",
+	}, []string{"route", "code"})
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "order_grpc_request_duration_seconds",
+		Help:    "Duration of handled gRPC calls by route",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route"})
+	registerer.MustRegister(requests, duration)
+
+	return &MetricsInterceptor{
+		requests: requests,
+		duration: duration,
+	}
+}
+
+type MetricsInterceptor struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func (i *MetricsInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		i.observe(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func (i *MetricsInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		i.observe(info.FullMethod, start, err)
+		return err
+	}
+}
+
+func (i *MetricsInterceptor) observe(route string, start time.Time, err error) {
+	i.duration.WithLabelValues(route).Observe(time.Since(start).Seconds())
+	i.requests.WithLabelValues(route, getGRPCCode(err).String()).Inc()
+}
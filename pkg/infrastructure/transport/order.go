@@ -0,0 +1,370 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	api "order/api/server/order"
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+)
+
+func NewOrderAPI(orderService service.OrderService) api.OrderServiceServer {
+	return &orderAPI{
+		orderService: orderService,
+	}
+}
+
+type orderAPI struct {
+	orderService service.OrderService
+}
+
+func (o *orderAPI) CreateOrder(ctx context.Context, req *api.CreateOrderRequest) (*api.CreateOrderResponse, error) {
+	customerID, err := parseID("customer_id", req.CustomerId)
+	if err != nil {
+		return nil, err
+	}
+
+	orderID, err := o.orderService.CreateOrder(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CreateOrderResponse{
+		OrderId: orderID.String(),
+	}, nil
+}
+
+func (o *orderAPI) GetOrder(ctx context.Context, req *api.GetOrderRequest) (*api.GetOrderResponse, error) {
+	orderID, err := parseID("order_id", req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := o.orderService.FindOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	err = sendETag(ctx, order.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.GetOrderResponse{
+		Order: toAPIOrder(order),
+	}, nil
+}
+
+func (o *orderAPI) ListOrders(ctx context.Context, req *api.ListOrdersRequest) (*api.ListOrdersResponse, error) {
+	spec := model.FindSpec{
+		IncludeDeleted: req.IncludeDeleted,
+	}
+	if req.CustomerId != "" {
+		customerID, err := parseID("customer_id", req.CustomerId)
+		if err != nil {
+			return nil, err
+		}
+		spec.CustomerID = &customerID
+	}
+	for _, apiStatus := range req.Statuses {
+		orderStatus, err := fromAPIStatus(apiStatus)
+		if err != nil {
+			return nil, err
+		}
+		spec.Statuses = append(spec.Statuses, orderStatus)
+	}
+	if req.CreatedFrom != nil {
+		createdFrom := req.CreatedFrom.AsTime()
+		spec.CreatedFrom = &createdFrom
+	}
+	if req.CreatedTo != nil {
+		createdTo := req.CreatedTo.AsTime()
+		spec.CreatedTo = &createdTo
+	}
+
+	after, err := decodePageToken(req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := pageSize(req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, nextPage, err := o.orderService.ListOrders(ctx, spec, model.Page{After: after, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	apiOrders := make([]*api.Order, 0, len(orders))
+	for i := range orders {
+		apiOrders = append(apiOrders, toAPIOrder(&orders[i]))
+	}
+	resp := &api.ListOrdersResponse{
+		Orders: apiOrders,
+	}
+	if nextPage != nil {
+		resp.NextPageToken = encodePageToken(nextPage.After)
+	}
+	return resp, nil
+}
+
+func (o *orderAPI) AddProduct(ctx context.Context, req *api.AddProductRequest) (*api.AddProductResponse, error) {
+	customerID, err := parseID("customer_id", req.CustomerId)
+	if err != nil {
+		return nil, err
+	}
+	productID, err := parseID("product_id", req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	orderID, itemID, err := o.orderService.AddProductToOrder(ctx, customerID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.AddProductResponse{
+		OrderId: orderID.String(),
+		ItemId:  itemID.String(),
+	}, nil
+}
+
+func (o *orderAPI) ChangeQuantity(ctx context.Context, req *api.ChangeQuantityRequest) (*api.ChangeQuantityResponse, error) {
+	orderID, err := parseID("order_id", req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	itemID, err := parseID("item_id", req.ItemId)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = withIfMatch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.orderService.ChangeItemQuantity(ctx, orderID, itemID, int(req.Quantity))
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.ChangeQuantityResponse{}, nil
+}
+
+func (o *orderAPI) RemoveItem(ctx context.Context, req *api.RemoveItemRequest) (*api.RemoveItemResponse, error) {
+	orderID, err := parseID("order_id", req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	itemID, err := parseID("item_id", req.ItemId)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = withIfMatch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.orderService.RemoveItem(ctx, orderID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.RemoveItemResponse{}, nil
+}
+
+func (o *orderAPI) SetStatus(ctx context.Context, req *api.SetStatusRequest) (*api.SetStatusResponse, error) {
+	orderID, err := parseID("order_id", req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	orderStatus, err := fromAPIStatus(req.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = withIfMatch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.orderService.SetStatus(ctx, orderID, orderStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.SetStatusResponse{}, nil
+}
+
+func (o *orderAPI) CancelOrder(ctx context.Context, req *api.CancelOrderRequest) (*api.CancelOrderResponse, error) {
+	orderID, err := parseID("order_id", req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	reason, err := fromAPICancellationReason(req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = withIfMatch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.orderService.CancelOrder(ctx, orderID, reason, req.Actor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CancelOrderResponse{}, nil
+}
+
+func (o *orderAPI) DeleteOrder(ctx context.Context, req *api.DeleteOrderRequest) (*api.DeleteOrderResponse, error) {
+	orderID, err := parseID("order_id", req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = withIfMatch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.orderService.DeleteOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.DeleteOrderResponse{}, nil
+}
+
+func (o *orderAPI) Checkout(ctx context.Context, req *api.CheckoutRequest) (*api.CheckoutResponse, error) {
+	orderID, err := parseID("order_id", req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = withIfMatch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := o.orderService.Checkout(ctx, orderID, req.AcceptPriceChanges)
+	if err != nil {
+		return nil, err
+	}
+
+	priceChanges := make([]*api.PriceChange, 0, len(changes))
+	for _, change := range changes {
+		priceChanges = append(priceChanges, &api.PriceChange{
+			ItemId:    change.ItemID.String(),
+			ProductId: change.ProductID.String(),
+			OldPrice:  toAPIMoney(change.OldPrice),
+			NewPrice:  toAPIMoney(change.NewPrice),
+		})
+	}
+	return &api.CheckoutResponse{
+		PriceChanges: priceChanges,
+	}, nil
+}
+
+func parseID(field, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, invalidFieldError(field, err.Error())
+	}
+	return id, nil
+}
+
+func toAPIOrder(order *model.Order) *api.Order {
+	items := make([]*api.Item, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, &api.Item{
+			ItemId:    item.ID.String(),
+			ProductId: item.ProductID.String(),
+			Price:     toAPIMoney(item.Price),
+			Quantity:  int32(item.Quantity),
+		})
+	}
+
+	totals := make([]*api.OrderTotal, 0, len(order.Totals))
+	for _, total := range order.Totals {
+		totals = append(totals, &api.OrderTotal{
+			Subtotal: toAPIMoney(total.Subtotal),
+			Discount: toAPIMoney(total.Discount),
+			Tax:      toAPIMoney(total.Tax),
+			Total:    toAPIMoney(total.Total),
+		})
+	}
+
+	return &api.Order{
+		OrderId:    order.ID.String(),
+		CustomerId: order.CustomerID.String(),
+		Status:     toAPIStatus(order.Status),
+		Version:    order.Version,
+		Items:      items,
+		CreatedAt:  timestamppb.New(order.CreatedAt),
+		UpdatedAt:  timestamppb.New(order.UpdatedAt),
+		Totals:     totals,
+	}
+}
+
+func toAPIMoney(money model.Money) *api.Money {
+	return &api.Money{
+		Amount:   money.Amount(),
+		Currency: money.Currency(),
+	}
+}
+
+func toAPIStatus(orderStatus model.OrderStatus) api.OrderStatus {
+	switch orderStatus {
+	case model.Open:
+		return api.OrderStatus_OPEN
+	case model.Pending:
+		return api.OrderStatus_PENDING
+	case model.Paid:
+		return api.OrderStatus_PAID
+	case model.Cancelled:
+		return api.OrderStatus_CANCELLED
+	default:
+		return api.OrderStatus_OPEN
+	}
+}
+
+func fromAPIStatus(orderStatus api.OrderStatus) (model.OrderStatus, error) {
+	switch orderStatus {
+	case api.OrderStatus_OPEN:
+		return model.Open, nil
+	case api.OrderStatus_PENDING:
+		return model.Pending, nil
+	case api.OrderStatus_PAID:
+		return model.Paid, nil
+	case api.OrderStatus_CANCELLED:
+		return model.Cancelled, nil
+	default:
+		return 0, invalidFieldError("status", fmt.Sprintf("unknown order status %v", orderStatus))
+	}
+}
+
+func fromAPICancellationReason(reason api.CancellationReason) (model.CancellationReason, error) {
+	switch reason {
+	case api.CancellationReason_CUSTOMER_REQUEST:
+		return model.CustomerRequest, nil
+	case api.CancellationReason_OUT_OF_STOCK:
+		return model.OutOfStock, nil
+	case api.CancellationReason_PAYMENT_FAILED:
+		return model.PaymentFailed, nil
+	case api.CancellationReason_FRAUD_SUSPECTED:
+		return model.FraudSuspected, nil
+	case api.CancellationReason_OTHER:
+		return model.OtherReason, nil
+	default:
+		return 0, invalidFieldError("reason", fmt.Sprintf("unknown cancellation reason %v", reason))
+	}
+}
@@ -0,0 +1,45 @@
+package transport
+
+import (
+	"encoding/base64"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// page tokens are opaque for clients, they hold the last order ID of the previous page
+func encodePageToken(after uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString(after[:])
+}
+
+func decodePageToken(token string) (uuid.UUID, error) {
+	if token == "" {
+		return uuid.Nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return uuid.Nil, invalidFieldError("page_token", "malformed token")
+	}
+	after, err := uuid.FromBytes(data)
+	if err != nil {
+		return uuid.Nil, invalidFieldError("page_token", "malformed token")
+	}
+	return after, nil
+}
+
+func pageSize(size int32) (int, error) {
+	switch {
+	case size < 0:
+		return 0, invalidFieldError("page_size", "must not be negative")
+	case size == 0:
+		return defaultPageSize, nil
+	case size > maxPageSize:
+		return maxPageSize, nil
+	default:
+		return int(size), nil
+	}
+}
@@ -0,0 +1,61 @@
+package transport
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPageToken(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		after := uuid.Must(uuid.NewV7())
+		decoded, err := decodePageToken(encodePageToken(after))
+		require.NoError(t, err)
+		require.Equal(t, after, decoded)
+	})
+
+	t.Run("Empty token starts from the first page", func(t *testing.T) {
+		decoded, err := decodePageToken("")
+		require.NoError(t, err)
+		require.Equal(t, uuid.Nil, decoded)
+	})
+
+	for name, token := range map[string]string{
+		"Not base64":   "not a token!",
+		"Wrong length": base64.RawURLEncoding.EncodeToString([]byte("short")),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := decodePageToken(token)
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int32
+		expected int
+	}{
+		{name: "Default", size: 0, expected: defaultPageSize},
+		{name: "Requested", size: 10, expected: 10},
+		{name: "Maximum", size: maxPageSize, expected: maxPageSize},
+		{name: "Clamped to maximum", size: maxPageSize + 1, expected: maxPageSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, err := pageSize(tt.size)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, size)
+		})
+	}
+
+	t.Run("Negative", func(t *testing.T) {
+		_, err := pageSize(-1)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,63 @@
+package transport
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewRecoveryInterceptor turns handler panics into Internal errors instead of crashing the process
+func NewRecoveryInterceptor(logger *log.Logger, registerer prometheus.Registerer) *RecoveryInterceptor {
+	panics := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "order_grpc_panics_total",
+		Help: "Panics recovered in gRPC handlers by route",
+	}, []string{"route"})
+	registerer.MustRegister(panics)
+
+	return &RecoveryInterceptor{
+		logger: logger,
+		panics: panics,
+	}
+}
+
+type RecoveryInterceptor struct {
+	logger *log.Logger
+	panics *prometheus.CounterVec
+}
+
+func (i *RecoveryInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = i.recovered(ctx, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func (i *RecoveryInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = i.recovered(stream.Context(), info.FullMethod, r)
+			}
+		}()
+		return handler(srv, stream)
+	}
+}
+
+// recovered does not expose the panic value to clients, it is logged with the stack instead
+func (i *RecoveryInterceptor) recovered(ctx context.Context, route string, r interface{}) error {
+	i.panics.WithLabelValues(route).Inc()
+	i.logger.WithContext(ctx).WithFields(log.Fields{
+		"route": route,
+		"stack": string(debug.Stack()),
+	}).Errorf("panic in handler: %v", r)
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,57 @@
+package transport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecoveryInterceptor(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	interceptor := NewRecoveryInterceptor(logger, prometheus.NewRegistry())
+	const route = "/Order.OrderService/GetOrder"
+
+	t.Run("Unary", func(t *testing.T) {
+		_, err := interceptor.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: route},
+			func(context.Context, interface{}) (interface{}, error) {
+				panic("implement me")
+			},
+		)
+		require.Equal(t, codes.Internal, status.Code(err))
+
+		entry := hook.LastEntry()
+		require.Equal(t, log.ErrorLevel, entry.Level)
+		require.Equal(t, route, entry.Data["route"])
+		require.Contains(t, entry.Data["stack"], "recovery_test.go")
+		require.InDelta(t, 1, testutil.ToFloat64(interceptor.panics.WithLabelValues(route)), 0)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		stream := &contextServerStream{ctx: context.Background()}
+		err := interceptor.Stream()(nil, stream, &grpc.StreamServerInfo{FullMethod: route},
+			func(interface{}, grpc.ServerStream) error {
+				panic("implement me")
+			},
+		)
+		require.Equal(t, codes.Internal, status.Code(err))
+		require.InDelta(t, 2, testutil.ToFloat64(interceptor.panics.WithLabelValues(route)), 0)
+	})
+
+	t.Run("No panic", func(t *testing.T) {
+		resp, err := interceptor.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: route},
+			func(context.Context, interface{}) (interface{}, error) {
+				return "ok", nil
+			},
+		)
+		require.NoError(t, err)
+		require.Equal(t, "ok", resp)
+	})
+}
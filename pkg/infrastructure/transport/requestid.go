@@ -0,0 +1,57 @@
+package transport
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"order/pkg/infrastructure/requestid"
+)
+
+// RequestIDInterceptor takes request id from x-request-id metadata or generates one when it is missing or malformed,
+// stores it in the call context and returns it to the client in the response header.
+// Valid x-correlation-id is stored as well, otherwise the request id correlates the flow
+func RequestIDInterceptor() Interceptor {
+	return Interceptor{
+		Unary: func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			ctx = withRequestID(ctx)
+			return handler(ctx, req)
+		},
+		Stream: func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &contextServerStream{
+				ServerStream: stream,
+				ctx:          withRequestID(stream.Context()),
+			})
+		},
+	}
+}
+
+func withRequestID(ctx context.Context) context.Context {
+	requestID := ""
+	if values := metadata.ValueFromIncomingContext(ctx, requestid.Header); len(values) > 0 {
+		requestID = values[0]
+	}
+	if !requestid.IsValid(requestID) {
+		requestID = requestid.New()
+	}
+
+	// header is best effort, it fails only when the call is not served by a GRPC server
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestid.Header, requestID))
+	ctx = requestid.WithRequestID(ctx, requestID)
+
+	if values := metadata.ValueFromIncomingContext(ctx, requestid.CorrelationHeader); len(values) > 0 && requestid.IsValid(values[0]) {
+		ctx = requestid.WithCorrelationID(ctx, values[0])
+	}
+	return ctx
+}
+
+// contextServerStream replaces context of a stream for handlers down the chain
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,74 @@
+package transport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"order/pkg/infrastructure/requestid"
+)
+
+func TestRequestIDInterceptor(t *testing.T) {
+	interceptor := RequestIDInterceptor()
+	unaryRequestID := func(ctx context.Context) string {
+		var requestID string
+		_, err := interceptor.Unary(ctx, nil, &grpc.UnaryServerInfo{},
+			func(ctx context.Context, _ interface{}) (interface{}, error) {
+				requestID = requestid.FromContext(ctx)
+				return nil, nil
+			},
+		)
+		require.NoError(t, err)
+		return requestID
+	}
+	withHeader := func(value string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestid.Header, value))
+	}
+
+	t.Run("Takes incoming id", func(t *testing.T) {
+		require.Equal(t, "req-42", unaryRequestID(withHeader("req-42")))
+	})
+
+	t.Run("Generates missing id", func(t *testing.T) {
+		requestID := unaryRequestID(context.Background())
+		require.True(t, requestid.IsValid(requestID))
+		require.NotEqual(t, requestID, unaryRequestID(context.Background()))
+	})
+
+	t.Run("Replaces malformed id", func(t *testing.T) {
+		requestID := unaryRequestID(withHeader("bad id"))
+		require.NotEqual(t, "bad id", requestID)
+		require.True(t, requestid.IsValid(requestID))
+	})
+
+	t.Run("Takes incoming correlation id", func(t *testing.T) {
+		var correlationID string
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+			requestid.Header, "req-42",
+			requestid.CorrelationHeader, "flow-1",
+		))
+		_, err := interceptor.Unary(ctx, nil, &grpc.UnaryServerInfo{},
+			func(ctx context.Context, _ interface{}) (interface{}, error) {
+				correlationID = requestid.CorrelationIDFromContext(ctx)
+				return nil, nil
+			},
+		)
+		require.NoError(t, err)
+		require.Equal(t, "flow-1", correlationID)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		var requestID string
+		err := interceptor.Stream(nil, &contextServerStream{ctx: withHeader("req-43")}, &grpc.StreamServerInfo{},
+			func(_ interface{}, stream grpc.ServerStream) error {
+				requestID = requestid.FromContext(stream.Context())
+				return nil
+			},
+		)
+		require.NoError(t, err)
+		require.Equal(t, "req-43", requestID)
+	})
+}
@@ -0,0 +1,36 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"order/pkg/application/service"
+)
+
+// order version works as an ETag: it is sent in etag header and may be required by if-match header of mutations
+const (
+	etagHeader    = "etag"
+	ifMatchHeader = "if-match"
+)
+
+func withIfMatch(ctx context.Context) (context.Context, error) {
+	values := metadata.ValueFromIncomingContext(ctx, ifMatchHeader)
+	if len(values) == 0 {
+		return ctx, nil
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(values[0], `"`), 10, 64)
+	if err != nil {
+		return nil, invalidFieldError(ifMatchHeader, fmt.Sprintf("%q is not an order version", values[0]))
+	}
+	return service.WithExpectedVersion(ctx, version), nil
+}
+
+func sendETag(ctx context.Context, version int64) error {
+	return grpc.SetHeader(ctx, metadata.Pairs(etagHeader, strconv.Quote(strconv.FormatInt(version, 10))))
+}
@@ -0,0 +1,129 @@
+package transport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	api "order/api/server/order"
+	"order/pkg/application/service"
+	"order/pkg/domain/model"
+	domainservice "order/pkg/domain/service"
+)
+
+func TestOrderVersion(t *testing.T) {
+	order := model.Order{ID: uuid.Must(uuid.NewV7()), Status: model.Open, Version: 3}
+	repo := &singleOrderRepository{order: order}
+	uow := singleOrderUnitOfWork{repo: repo}
+	orderAPI := NewOrderAPI(service.NewOrderService(
+		uow,
+		singleOrderLockableUnitOfWork{uow},
+		discardingDispatcher{},
+		unknownProductProvider{},
+		unknownProductProvider{},
+		domainservice.Options{},
+	))
+
+	t.Run("GetOrder sends ETag", func(t *testing.T) {
+		stream := &headerRecordingStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+
+		_, err := orderAPI.GetOrder(ctx, &api.GetOrderRequest{OrderId: order.ID.String()})
+		require.NoError(t, err)
+		require.Equal(t, []string{`"3"`}, stream.header.Get(etagHeader))
+	})
+
+	withIfMatchHeader := func(value string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(ifMatchHeader, value))
+	}
+	setStatus := func(ctx context.Context) error {
+		_, err := orderAPI.SetStatus(ctx, &api.SetStatusRequest{OrderId: order.ID.String(), Status: api.OrderStatus_PENDING})
+		return err
+	}
+
+	t.Run("Mismatched If-Match is rejected", func(t *testing.T) {
+		err := setStatus(withIfMatchHeader(`"2"`))
+		require.ErrorIs(t, err, model.ErrConcurrentModification)
+		require.Equal(t, codes.Aborted, getGRPCCode(err))
+		require.Equal(t, model.Open, repo.order.Status)
+	})
+
+	t.Run("Malformed If-Match", func(t *testing.T) {
+		err := setStatus(withIfMatchHeader("latest"))
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Matching If-Match is accepted", func(t *testing.T) {
+		require.NoError(t, setStatus(withIfMatchHeader(`"3"`)))
+		require.Equal(t, model.Pending, repo.order.Status)
+		require.Equal(t, int64(4), repo.order.Version)
+	})
+}
+
+type singleOrderUnitOfWork struct {
+	repo *singleOrderRepository
+}
+
+func (u singleOrderUnitOfWork) Execute(_ context.Context, f func(provider service.RepositoryProvider) error) error {
+	return f(u)
+}
+
+func (u singleOrderUnitOfWork) OrderRepository(context.Context) model.OrderRepository {
+	return u.repo
+}
+
+func (u singleOrderUnitOfWork) IdempotencyRepository(context.Context) service.IdempotencyRepository {
+	panic("unexpected idempotency repository")
+}
+
+type singleOrderLockableUnitOfWork struct {
+	singleOrderUnitOfWork
+}
+
+func (u singleOrderLockableUnitOfWork) Execute(ctx context.Context, _ string, f func(provider service.RepositoryProvider) error) error {
+	return u.singleOrderUnitOfWork.Execute(ctx, f)
+}
+
+type singleOrderRepository struct {
+	model.OrderRepository
+	order model.Order
+}
+
+func (r *singleOrderRepository) Find(model.FindSpec) (*model.Order, error) {
+	order := r.order
+	return &order, nil
+}
+
+func (r *singleOrderRepository) Store(order *model.Order) error {
+	order.Version++
+	r.order = *order
+	return nil
+}
+
+type discardingDispatcher struct{}
+
+func (discardingDispatcher) Dispatch(context.Context, domainservice.Event) error {
+	return nil
+}
+
+type headerRecordingStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *headerRecordingStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+type unknownProductProvider struct{}
+
+func (unknownProductProvider) ActualPrice(context.Context, uuid.UUID) (model.Money, error) {
+	return model.Money{}, model.ErrProductNotFound
+}